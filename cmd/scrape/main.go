@@ -0,0 +1,74 @@
+// Command scrape runs a single scrape synchronously and prints the run report as JSON.
+// With -dry-run nothing is written or sent; the report lists what would have been.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"ponisha-go/internal/app"
+	"ponisha-go/internal/config"
+	"ponisha-go/internal/logging"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "scrape and filter without saving projects or sending alerts")
+	sourceList := flag.String("sources", "", "comma-separated providers to run (default: all)")
+	flag.Parse()
+
+	var sources []string
+	for _, source := range strings.Split(*sourceList, ",") {
+		if source = strings.TrimSpace(source); source != "" {
+			sources = append(sources, source)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("config error", err)
+	}
+	if err := logging.Setup(cfg.LogFormat, cfg.LogLevel); err != nil {
+		fatal("logging setup error", err)
+	}
+
+	ctx := context.Background()
+	application, err := app.NewBuilder(&cfg).Build(ctx)
+	if err != nil {
+		fatal("app build error", err)
+	}
+
+	run := application.ScrapeService.RunSync
+	if *dryRun {
+		run = application.ScrapeService.DryRun
+	}
+	report, runErr := run(ctx, sources...)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := application.Shutdown(shutdownCtx); err != nil {
+		slog.Error("shutdown error", "error", err)
+	}
+
+	if runErr != nil {
+		fatal("scrape error", runErr)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		fatal("encode report", err)
+	}
+	if len(report.Errors) > 0 {
+		os.Exit(1)
+	}
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
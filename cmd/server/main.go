@@ -2,7 +2,7 @@ package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
@@ -10,23 +10,27 @@ import (
 
 	"ponisha-go/internal/app"
 	"ponisha-go/internal/config"
+	"ponisha-go/internal/logging"
 )
 
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("config error: %v", err)
+		fatal("config error", err)
+	}
+	if err := logging.Setup(cfg.LogFormat, cfg.LogLevel); err != nil {
+		fatal("logging setup error", err)
 	}
 
 	ctx := context.Background()
 	builder := app.NewBuilder(&cfg)
 	application, err := builder.Build(ctx)
 	if err != nil {
-		log.Fatalf("app build error: %v", err)
+		fatal("app build error", err)
 	}
 
 	if err := application.Start(); err != nil {
-		log.Fatalf("app start error: %v", err)
+		fatal("app start error", err)
 	}
 
 	waitForShutdown(application)
@@ -37,12 +41,17 @@ func waitForShutdown(application *app.App) {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	<-sigChan
 
-	log.Printf("shutdown signal received")
+	slog.Info("shutdown signal received")
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := application.Shutdown(ctx); err != nil {
-		log.Printf("server shutdown error: %v", err)
+		slog.Error("server shutdown error", "error", err)
 	}
 }
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
@@ -4,6 +4,29 @@ FROM projects
 WHERE source = $1 AND external_id = $2
 LIMIT 1;
 
+-- name: ListLatestProjects :many
+SELECT id, source, external_id, title, link, budget_text, amount_min, amount_max, created_at
+FROM projects
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
+
+-- name: SearchProjects :many
+SELECT id, source, external_id, title, link, budget_text, amount_min, amount_max, created_at
+FROM projects
+WHERE title ILIKE '%' || sqlc.arg(query)::text || '%'
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(row_limit);
+
+-- name: ProjectStatsBySource :many
+SELECT
+  source,
+  COUNT(*)::bigint AS total,
+  COUNT(*) FILTER (WHERE created_at >= $1)::bigint AS recent,
+  MAX(created_at)::timestamptz AS last_created_at
+FROM projects
+GROUP BY source
+ORDER BY source;
+
 -- name: CreateProjectIfNotExists :one
 INSERT INTO projects (
   source,
@@ -16,3 +39,45 @@ INSERT INTO projects (
 ) VALUES ($1, $2, $3, $4, $5, $6, $7)
 ON CONFLICT (source, external_id) DO NOTHING
 RETURNING id, source, external_id, title, link, budget_text, amount_min, amount_max, created_at;
+
+-- name: UpsertTriage :exec
+INSERT INTO project_triage (
+  source,
+  external_id,
+  user_id,
+  user_name,
+  state,
+  chat_id,
+  message_id,
+  remind_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (source, external_id, user_id) DO UPDATE SET
+  user_name = EXCLUDED.user_name,
+  state = EXCLUDED.state,
+  chat_id = EXCLUDED.chat_id,
+  message_id = EXCLUDED.message_id,
+  remind_at = EXCLUDED.remind_at,
+  reminded_at = NULL,
+  updated_at = NOW();
+
+-- name: ListTriageByProject :many
+SELECT source, external_id, user_id, user_name, state, chat_id, message_id, remind_at, reminded_at, updated_at
+FROM project_triage
+WHERE source = $1 AND external_id = $2
+ORDER BY updated_at;
+
+-- name: ClaimDueReminders :many
+UPDATE project_triage t
+SET reminded_at = NOW()
+FROM projects p
+WHERE p.source = t.source
+  AND p.external_id = t.external_id
+  AND (t.source, t.external_id, t.user_id) IN (
+    SELECT d.source, d.external_id, d.user_id
+    FROM project_triage d
+    WHERE d.state = 'remind' AND d.reminded_at IS NULL AND d.remind_at <= $1
+    ORDER BY d.remind_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+  )
+RETURNING t.source, t.external_id, t.user_id, t.user_name, t.state, t.chat_id, t.message_id, t.remind_at, t.reminded_at, t.updated_at, p.title, p.link;
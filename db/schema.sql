@@ -11,3 +11,21 @@ CREATE TABLE IF NOT EXISTS projects (
 );
 
 CREATE UNIQUE INDEX IF NOT EXISTS uq_source_external ON projects (source, external_id);
+
+-- project_triage holds what each Telegram user chose for an alerted project.
+CREATE TABLE IF NOT EXISTS project_triage (
+  source TEXT NOT NULL,
+  external_id TEXT NOT NULL,
+  user_id BIGINT NOT NULL,
+  user_name TEXT NOT NULL,
+  state TEXT NOT NULL,
+  chat_id BIGINT NOT NULL,
+  message_id BIGINT NOT NULL,
+  remind_at TIMESTAMPTZ,
+  reminded_at TIMESTAMPTZ,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (source, external_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_project_triage_due ON project_triage (remind_at)
+  WHERE state = 'remind' AND reminded_at IS NULL;
@@ -2,15 +2,20 @@ package app
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"ponisha-go/internal/config"
+	"ponisha-go/internal/metrics"
 	"ponisha-go/internal/repositories"
 	"ponisha-go/internal/scheduler"
 	"ponisha-go/internal/services/scraping"
+	"ponisha-go/internal/telegram"
 )
 
 type App struct {
@@ -22,32 +27,78 @@ type App struct {
 	ScrapeService *scraping.Service
 	Scheduler     *scheduler.Scheduler
 	Server        *http.Server
+	Metrics       *metrics.Metrics
+	// Updates consumes Telegram bot updates; nil when TELEGRAM_UPDATES is off.
+	Updates *telegram.Consumer
 
-	ownsPool bool
+	ownsPool    bool
+	stopTracing func(context.Context) error
 }
 
 func (a *App) Start() error {
 	if err := a.Scheduler.Start(); err != nil {
 		return err
 	}
+	if a.Updates != nil {
+		if err := a.Updates.Start(context.Background()); err != nil {
+			return err
+		}
+	}
 
 	go func() {
-		log.Printf("HTTP server listening on %s", a.Server.Addr)
+		slog.Info("HTTP server listening", "addr", a.Server.Addr)
 		if err := a.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("http server error: %v", err)
+			slog.Error("http server error", "error", err)
+			os.Exit(1)
 		}
 	}()
 
 	return nil
 }
 
+// closer is implemented by notifiers that queue messages and can flush them on shutdown.
+type closer interface {
+	Close(ctx context.Context) error
+}
+
+// Shutdown stops the app. The scrape service is shut down alongside the HTTP server so that
+// ?wait and ?dryRun requests still running are cancelled rather than waited out. Every step runs
+// even when an earlier one fails; their errors are joined.
 func (a *App) Shutdown(ctx context.Context) error {
 	a.Scheduler.Stop()
+
+	var serviceErr error
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		serviceErr = a.ScrapeService.Shutdown(ctx)
+	}()
+	var errs []error
 	if err := a.Server.Shutdown(ctx); err != nil {
-		return err
+		errs = append(errs, fmt.Errorf("http server shutdown: %w", err))
+	}
+	<-stopped
+	if serviceErr != nil {
+		errs = append(errs, fmt.Errorf("scrape service shutdown: %w", serviceErr))
+	}
+
+	if a.Updates != nil {
+		if err := a.Updates.Stop(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("telegram updates stop: %w", err))
+		}
+	}
+	if c, ok := a.Notifier.(closer); ok {
+		if err := c.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("notifier close: %w", err))
+		}
 	}
 	if a.ownsPool {
 		a.Pool.Close()
 	}
-	return nil
+	if a.stopTracing != nil {
+		if err := a.stopTracing(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("tracing shutdown: %w", err))
+		}
+	}
+	return errors.Join(errs...)
 }
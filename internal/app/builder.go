@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -14,13 +15,17 @@ import (
 	"ponisha-go/internal/db"
 	dbsqlc "ponisha-go/internal/db/sqlc"
 	"ponisha-go/internal/httpapi"
+	"ponisha-go/internal/metrics"
+	"ponisha-go/internal/notifiers/composite"
 	"ponisha-go/internal/providers/karlancer"
 	"ponisha-go/internal/providers/ponisha"
+	"ponisha-go/internal/providers/session"
 	"ponisha-go/internal/repositories"
 	sqlcrepo "ponisha-go/internal/repositories/sqlc"
+	"ponisha-go/internal/robots"
 	"ponisha-go/internal/scheduler"
 	"ponisha-go/internal/services/scraping"
-	"ponisha-go/internal/telegram"
+	"ponisha-go/internal/tracing"
 )
 
 type Builder struct {
@@ -28,16 +33,25 @@ type Builder struct {
 	basePath     string
 	ensureSchema bool
 
-	pool     *pgxpool.Pool
-	repo     repositories.ProjectRepository
-	notifier scraping.Notifier
-	scrapers []scraping.SiteScraper
-	client   *http.Client
+	pool      *pgxpool.Pool
+	repo      repositories.ProjectRepository
+	triage    repositories.TriageRepository
+	notifiers []composite.Child
+	scrapers  []scraping.SiteScraper
+	client    *http.Client
+	metrics   *metrics.Metrics
+
+	interceptors []stageInterceptor
 
 	scheduler *scheduler.Scheduler
 	server    *http.Server
 }
 
+type stageInterceptor struct {
+	stage       scraping.Stage
+	interceptor scraping.Interceptor
+}
+
 type BuilderOption func(*Builder)
 
 func NewBuilder(cfg *config.Config, options ...BuilderOption) *Builder {
@@ -75,9 +89,27 @@ func WithRepository(repo repositories.ProjectRepository) BuilderOption {
 	}
 }
 
+// WithNotifier replaces the configured notifiers. Given more than once, alerts fan out to
+// every notifier through a composite notifier.
 func WithNotifier(notifier scraping.Notifier) BuilderOption {
 	return func(b *Builder) {
-		b.notifier = notifier
+		b.notifiers = append(b.notifiers, composite.Child{
+			Name:     fmt.Sprintf("notifier-%d", len(b.notifiers)+1),
+			Notifier: notifier,
+		})
+	}
+}
+
+// WithNamedNotifier is WithNotifier with the name the notifier is logged under.
+func WithNamedNotifier(name string, notifier scraping.Notifier) BuilderOption {
+	return func(b *Builder) {
+		b.notifiers = append(b.notifiers, composite.Child{Name: name, Notifier: notifier})
+	}
+}
+
+func WithTriageRepository(repo repositories.TriageRepository) BuilderOption {
+	return func(b *Builder) {
+		b.triage = repo
 	}
 }
 
@@ -93,6 +125,21 @@ func WithHTTPClient(client *http.Client) BuilderOption {
 	}
 }
 
+// WithStageInterceptor plugs interceptor into one stage of the scrape pipeline, e.g. to
+// enrich or tag projects, add filters or push saved projects elsewhere. Interceptors for the
+// same stage run in the order they are given, the first one outermost.
+func WithStageInterceptor(stage scraping.Stage, interceptor scraping.Interceptor) BuilderOption {
+	return func(b *Builder) {
+		b.interceptors = append(b.interceptors, stageInterceptor{stage: stage, interceptor: interceptor})
+	}
+}
+
+func WithMetrics(metrics *metrics.Metrics) BuilderOption {
+	return func(b *Builder) {
+		b.metrics = metrics
+	}
+}
+
 func WithScheduler(scheduler *scheduler.Scheduler) BuilderOption {
 	return func(b *Builder) {
 		b.scheduler = scheduler
@@ -119,9 +166,16 @@ func (b *Builder) Build(ctx context.Context) (*App, error) {
 		basePath = wd
 	}
 
-	app := &App{Config: b.cfg}
+	if b.metrics == nil {
+		b.metrics = metrics.New()
+	}
+	stopTracing, err := tracing.Setup(ctx, b.cfg.TracingExporter, b.cfg.TracingServiceName)
+	if err != nil {
+		return nil, err
+	}
+	app := &App{Config: b.cfg, Metrics: b.metrics, stopTracing: stopTracing}
 	if b.pool == nil {
-		pool, err := db.NewPool(ctx, b.cfg.PostgresDSN())
+		pool, err := db.NewPool(ctx, b.cfg.PostgresDSN(), b.metrics.QueryTracer(), tracing.QueryTracer())
 		if err != nil {
 			return nil, err
 		}
@@ -145,33 +199,88 @@ func (b *Builder) Build(ctx context.Context) (*App, error) {
 		b.repo = sqlcrepo.NewProjectRepository(queries)
 	}
 	app.Repo = b.repo
-
-	if b.notifier == nil {
-		b.notifier = telegram.NewSender(b.cfg.TelegramToken, b.cfg.TelegramChat, b.cfg.TelegramThreadID)
+	if b.triage == nil {
+		b.triage = sqlcrepo.NewTriageRepository(dbsqlc.New(b.pool))
 	}
-	app.Notifier = b.notifier
+	if len(b.notifiers) == 0 {
+		children, err := b.configuredNotifiers()
+		if err != nil {
+			return nil, err
+		}
+		b.notifiers = children
+	}
+	app.Notifier = b.combineNotifiers()
 
 	if b.client == nil {
-		b.client = &http.Client{Timeout: 15 * time.Second}
+		b.client = &http.Client{Timeout: 15 * time.Second, Transport: tracing.Transport(nil)}
 	}
 
 	if b.scrapers == nil {
+		ponishaOptions := []ponisha.Option{ponisha.WithMetrics(b.metrics), ponisha.WithUserAgent(b.cfg.UserAgent)}
+		karlancerOptions := []karlancer.Option{karlancer.WithMetrics(b.metrics), karlancer.WithUserAgent(b.cfg.UserAgent)}
+		if b.cfg.RobotsEnabled {
+			checker := robots.NewChecker(b.client, b.cfg.UserAgent)
+			ponishaOptions = append(ponishaOptions, ponisha.WithRobots(checker))
+			karlancerOptions = append(karlancerOptions, karlancer.WithRobots(checker))
+		}
+		ponishaClient, err := b.providerClient("ponisha", b.cfg.PonishaAccount, ponisha.Login(b.cfg.PonishaAccount.LoginURL))
+		if err != nil {
+			return nil, err
+		}
+		karlancerClient, err := b.providerClient("karlancer", b.cfg.KarlancerAccount, karlancer.Login(b.cfg.KarlancerAccount.LoginURL))
+		if err != nil {
+			return nil, err
+		}
 		b.scrapers = []scraping.SiteScraper{
-			ponisha.NewScraper(b.client),
-			karlancer.NewScraper(b.client),
+			ponisha.NewScraper(ponishaClient, ponishaOptions...),
+			karlancer.NewScraper(karlancerClient, karlancerOptions...),
 		}
 	}
 	app.Scrapers = b.scrapers
 
-	app.ScrapeService = scraping.NewService(app.Repo, app.Notifier, app.Scrapers)
+	serviceOptions := []scraping.Option{
+		scraping.WithHealthPolicy(scraping.HealthPolicy{
+			DownAfter:   b.cfg.ProviderDownAfter,
+			BaseBackoff: b.cfg.ProviderBackoffBase,
+			MaxBackoff:  b.cfg.ProviderBackoffMax,
+		}),
+		scraping.WithRunTimeout(b.cfg.ScrapeRunTimeout),
+		scraping.WithMetrics(b.metrics),
+		scraping.WithNotifyTimeout(b.cfg.NotifyEnqueueTimeout),
+		scraping.WithThreshold(b.cfg.BudgetThreshold),
+	}
+	if b.cfg.ScrapeLockScope != "off" {
+		serviceOptions = append(serviceOptions,
+			scraping.WithLocker(db.NewAdvisoryLocker(b.pool), b.cfg.ScrapeLockScope, b.cfg.ReplicaID),
+		)
+	}
+	for _, ic := range b.interceptors {
+		serviceOptions = append(serviceOptions, scraping.WithInterceptor(ic.stage, ic.interceptor))
+	}
+	app.ScrapeService = scraping.NewService(app.Repo, app.Notifier, app.Scrapers, serviceOptions...)
 
 	if b.scheduler == nil {
-		b.scheduler = scheduler.New(b.cfg.CronSpec, app.ScrapeService)
+		options := []scheduler.Option{scheduler.WithJitter(b.cfg.CronJitter)}
+		for source, schedule := range b.cfg.ProviderSchedules {
+			options = append(options, scheduler.WithSourceSchedule(source, scheduler.Schedule{
+				Spec:   schedule.Spec,
+				Jitter: schedule.Jitter,
+			}))
+		}
+		b.scheduler = scheduler.New(b.cfg.CronSpec, app.ScrapeService, options...)
 	}
 	app.Scheduler = b.scheduler
 
+	if b.cfg.TelegramUpdates != "off" {
+		app.Updates = b.telegramConsumer(app.ScrapeService, app.Scheduler)
+	}
+
 	if b.server == nil {
-		handler := httpapi.NewHandler(app.ScrapeService)
+		handlerOptions := []httpapi.Option{httpapi.WithMetrics(b.metrics.Handler())}
+		if app.Updates != nil && b.cfg.TelegramUpdates == "webhook" {
+			handlerOptions = append(handlerOptions, httpapi.WithTelegramWebhook(app.Updates.Handler()))
+		}
+		handler := httpapi.NewHandler(app.ScrapeService, handlerOptions...)
 		b.server = &http.Server{
 			Addr:              ":" + b.cfg.HTTPPort,
 			Handler:           handler.Router(),
@@ -182,3 +291,18 @@ func (b *Builder) Build(ctx context.Context) (*App, error) {
 
 	return app, nil
 }
+
+// providerClient returns the shared client, or an authenticated session client when the
+// provider has an account configured.
+func (b *Builder) providerClient(name string, account config.ProviderAccount, login session.LoginFunc) (*http.Client, error) {
+	creds := session.Credentials{Username: account.Username, Password: account.Password}
+	if creds.Empty() {
+		return b.client, nil
+	}
+	store := session.NewFileStore(filepath.Join(b.cfg.SessionDir, name+".json"))
+	sess, err := session.New(name, b.client, creds, login, store)
+	if err != nil {
+		return nil, err
+	}
+	return sess.Client(), nil
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	dbsqlc "ponisha-go/internal/db/sqlc"
+	"ponisha-go/internal/notifiers"
+	"ponisha-go/internal/notifiers/composite"
+	"ponisha-go/internal/notifiers/discord"
+	"ponisha-go/internal/notifiers/email"
+	"ponisha-go/internal/notifiers/slack"
+	"ponisha-go/internal/notifiers/webhook"
+	"ponisha-go/internal/repositories"
+	sqlcrepo "ponisha-go/internal/repositories/sqlc"
+	"ponisha-go/internal/scheduler"
+	"ponisha-go/internal/services/scraping"
+	"ponisha-go/internal/telegram"
+	"ponisha-go/internal/tracing"
+)
+
+// configuredNotifiers builds the notifiers named in NOTIFIERS.
+func (b *Builder) configuredNotifiers() ([]composite.Child, error) {
+	children := make([]composite.Child, 0, len(b.cfg.Notifiers))
+	for _, name := range b.cfg.Notifiers {
+		var notifier scraping.Notifier
+		switch name {
+		case "telegram":
+			options := []telegram.Option{
+				telegram.WithMetrics(b.metrics),
+				telegram.WithQueueSize(b.cfg.TelegramQueueSize),
+				telegram.WithHTTPClient(b.notifierClient()),
+			}
+			if b.cfg.TelegramUpdates != "off" {
+				options = append(options, telegram.WithTriageButtons())
+			}
+			sender := telegram.NewSender(b.cfg.TelegramToken, b.cfg.TelegramChat, b.cfg.TelegramThreadID, options...)
+			b.metrics.WatchQueue("telegram", sender.QueueDepth)
+			notifier = b.digested(sender, b.cfg.TelegramDigest)
+		case "slack":
+			sender := slack.New(b.cfg.SlackWebhookURL,
+				slack.WithMetrics(b.metrics),
+				slack.WithQueueSize(b.cfg.NotifyQueueSize),
+				slack.WithHTTPClient(b.notifierClient()),
+			)
+			b.metrics.WatchQueue("slack", sender.QueueDepth)
+			notifier = b.digested(sender, b.cfg.SlackDigest)
+		case "discord":
+			sender := discord.New(b.cfg.DiscordWebhookURL,
+				discord.WithMetrics(b.metrics),
+				discord.WithQueueSize(b.cfg.NotifyQueueSize),
+				discord.WithHTTPClient(b.notifierClient()),
+			)
+			b.metrics.WatchQueue("discord", sender.QueueDepth)
+			notifier = b.digested(sender, b.cfg.DiscordDigest)
+		case "email":
+			sender, err := email.New(b.emailServer(), b.cfg.SMTP.From, b.emailRecipients(),
+				email.WithMetrics(b.metrics),
+				email.WithQueueSize(b.cfg.NotifyQueueSize),
+				email.WithDigestOrder(b.cfg.DigestOrder),
+				email.WithDigestLimit(b.cfg.DigestLimit),
+			)
+			if err != nil {
+				return nil, err
+			}
+			b.metrics.WatchQueue("email", sender.QueueDepth)
+			notifier = sender
+		case "webhook":
+			sender := webhook.New(b.cfg.Webhook.URL, b.cfg.Webhook.Secret,
+				webhook.WithMetrics(b.metrics),
+				webhook.WithQueueSize(b.cfg.NotifyQueueSize),
+				webhook.WithHTTPClient(b.notifierClient()),
+				webhook.WithReplica(b.cfg.ReplicaID),
+				webhook.WithRetry(b.cfg.Webhook.MaxAttempts, b.cfg.Webhook.BackoffBase, b.cfg.Webhook.BackoffMax),
+				webhook.WithDeadLetterFile(b.cfg.Webhook.DeadLetterFile),
+			)
+			b.metrics.WatchQueue("webhook", sender.QueueDepth)
+			notifier = b.digested(sender, b.cfg.WebhookDigest)
+		default:
+			return nil, fmt.Errorf("unknown notifier %q", name)
+		}
+		children = append(children, composite.Child{Name: name, Notifier: notifier})
+	}
+	return children, nil
+}
+
+// digested puts sender in digest mode when schedule is enabled.
+func (b *Builder) digested(sender notifiers.DigestSender, schedule notifiers.DigestSchedule) scraping.Notifier {
+	if !schedule.Enabled() {
+		return sender
+	}
+	return notifiers.NewDigestNotifier(sender, schedule, b.cfg.DigestOrder, notifiers.WithDigestLimit(b.cfg.DigestLimit))
+}
+
+// combineNotifiers returns the only notifier as is, or a composite that fans out to all of them.
+func (b *Builder) combineNotifiers() scraping.Notifier {
+	if len(b.notifiers) == 1 {
+		return b.notifiers[0].Notifier
+	}
+	fanOut := composite.New(b.notifiers,
+		composite.WithQueueSize(b.cfg.NotifyQueueSize),
+		composite.WithMetrics(b.metrics),
+	)
+	for _, child := range b.notifiers {
+		name := child.Name
+		b.metrics.WatchQueue("fanout:"+name, func() int { return fanOut.QueueDepth(name) })
+	}
+	return fanOut
+}
+
+// telegramConsumer handles bot updates: triage button presses, reminders and bot commands.
+func (b *Builder) telegramConsumer(service *scraping.Service, sched *scheduler.Scheduler) *telegram.Consumer {
+	projects, ok := b.repo.(repositories.ProjectQueries)
+	if !ok {
+		projects = sqlcrepo.NewProjectRepository(dbsqlc.New(b.pool))
+	}
+	options := []telegram.ConsumerOption{
+		telegram.WithRemindBefore(b.cfg.TelegramRemindBefore),
+		telegram.WithConsumerHTTPClient(&http.Client{Timeout: 45 * time.Second, Transport: tracing.Transport(nil)}),
+		telegram.WithCommands(b.cfg.TelegramChat, scraperControl{service, sched}, projects, b.cfg.TelegramAdminIDs),
+	}
+	if b.cfg.TelegramUpdates == "webhook" {
+		options = append(options, telegram.WithWebhook(b.cfg.TelegramWebhookURL, b.cfg.TelegramWebhookSecret))
+	}
+	return telegram.NewConsumer(b.cfg.TelegramToken, b.triage, options...)
+}
+
+// scraperControl lets bot commands drive the scrape service and pause the scheduler.
+type scraperControl struct {
+	*scraping.Service
+	*scheduler.Scheduler
+}
+
+func (b *Builder) emailServer() email.Server {
+	return email.Server{
+		Host:     b.cfg.SMTP.Host,
+		Port:     b.cfg.SMTP.Port,
+		Username: b.cfg.SMTP.Username,
+		Password: b.cfg.SMTP.Password,
+		StartTLS: b.cfg.SMTP.StartTLS,
+	}
+}
+
+func (b *Builder) emailRecipients() []email.Recipient {
+	recipients := make([]email.Recipient, 0, len(b.cfg.EmailRecipients))
+	for _, r := range b.cfg.EmailRecipients {
+		digest := b.cfg.EmailDigest
+		if r.Digest != nil {
+			digest = *r.Digest
+		}
+		recipients = append(recipients, email.Recipient{
+			Address: r.Address,
+			Filter: email.Filter{
+				Sources:   r.Sources,
+				Skills:    r.Skills,
+				Keywords:  r.Keywords,
+				MinBudget: r.MinBudget,
+			},
+			Digest: digest,
+		})
+	}
+	return recipients
+}
+
+func (b *Builder) notifierClient() *http.Client {
+	return &http.Client{Timeout: 15 * time.Second, Transport: tracing.Transport(nil)}
+}
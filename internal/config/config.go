@@ -3,10 +3,17 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+	"ponisha-go/internal/providers/common"
 )
 
 type Config struct {
@@ -17,29 +24,182 @@ type Config struct {
 	DBName     string
 	DBSSLMode  string
 
+	// ReplicaID names this process in lock ownership and as the Postgres application_name.
+	ReplicaID string
+	// ScrapeLockScope is "source", "run" or "off".
+	ScrapeLockScope string
+
 	TelegramToken    string
 	TelegramChat     string
 	TelegramThreadID *int
+	// TelegramQueueSize is how many messages may wait to be sent.
+	TelegramQueueSize int
+	// TelegramUpdates is "off", "poll" or "webhook"; with updates on, alerts get triage buttons.
+	TelegramUpdates       string
+	TelegramWebhookURL    string
+	TelegramWebhookSecret string
+	TelegramRemindBefore  time.Duration
+	// TelegramAdminIDs are the user IDs allowed to run /pause, /resume, /scrape and to change
+	// the threshold.
+	TelegramAdminIDs []int64
+
+	// BudgetThreshold is the budget, in toman, a project must exceed to be saved and alerted.
+	BudgetThreshold int64
+
+	// SlackWebhookURL is the incoming webhook the "slack" notifier posts to.
+	SlackWebhookURL string
+	// DiscordWebhookURL is the webhook the "discord" notifier posts to.
+	DiscordWebhookURL string
+	// SMTP configures the "email" notifier; EmailRecipients says who gets which alerts.
+	SMTP            SMTPConfig
+	EmailRecipients []EmailRecipient
+	// Webhook configures the "webhook" notifier.
+	Webhook WebhookConfig
+
+	// The *Digest schedules batch each channel's alerts into one summary per window instead
+	// of a message per project; EMAIL_RECIPIENTS entries can override EmailDigest. DigestOrder
+	// is "budget" or "score". DigestLimit caps the projects one digest holds.
+	TelegramDigest notifiers.DigestSchedule
+	SlackDigest    notifiers.DigestSchedule
+	DiscordDigest  notifiers.DigestSchedule
+	WebhookDigest  notifiers.DigestSchedule
+	EmailDigest    notifiers.DigestSchedule
+	DigestOrder    notifiers.DigestOrder
+	DigestLimit    int
+
+	// Notifiers lists the alert channels; with more than one, alerts fan out to all of them.
+	Notifiers []string
+	// NotifyQueueSize is the per-channel queue size when alerts fan out.
+	NotifyQueueSize int
+	// NotifyEnqueueTimeout bounds how long a run waits for room in a full notifier queue.
+	NotifyEnqueueTimeout time.Duration
+
+	// LogFormat is "text" or "json"; LogLevel is "debug", "info", "warn" or "error".
+	LogFormat string
+	LogLevel  string
+	// TracingExporter is "none", "stdout" or "otlp".
+	TracingExporter    string
+	TracingServiceName string
+
+	HTTPPort         string
+	CronSpec         string
+	CronJitter       time.Duration
+	ScrapeRunTimeout time.Duration
+	// ProviderSchedules overrides CronSpec per source, keyed by lower-case source name.
+	ProviderSchedules map[string]Schedule
+
+	ProviderDownAfter   int
+	ProviderBackoffBase time.Duration
+	ProviderBackoffMax  time.Duration
+
+	RobotsEnabled bool
+	// UserAgent is sent on provider requests and used to pick the robots.txt rules that apply.
+	UserAgent string
+
+	SessionDir       string
+	PonishaAccount   ProviderAccount
+	KarlancerAccount ProviderAccount
+}
+
+// Schedule is a cron spec (including "@every 5m" intervals) plus a random start delay.
+type Schedule struct {
+	Spec   string
+	Jitter time.Duration
+}
+
+// SMTPConfig is the submission server for email alerts. An empty Username skips AUTH.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	StartTLS bool
+}
+
+// WebhookConfig is the endpoint signed JSON alerts are posted to. Failed requests are retried
+// MaxAttempts times in all, backing off from BackoffBase to BackoffMax, then appended to
+// DeadLetterFile (when set).
+type WebhookConfig struct {
+	URL            string
+	Secret         string
+	MaxAttempts    int
+	BackoffBase    time.Duration
+	BackoffMax     time.Duration
+	DeadLetterFile string
+}
 
-	HTTPPort string
-	CronSpec string
+// EmailRecipient is one EMAIL_RECIPIENTS entry: an address and optional filters, each a
+// comma-separated list except MinBudget (toman).
+type EmailRecipient struct {
+	Address   string
+	Sources   []string
+	Skills    []string
+	Keywords  []string
+	MinBudget int64
+	// Digest overrides EMAIL_DIGEST for this recipient when set.
+	Digest *notifiers.DigestSchedule
+}
+
+// ProviderAccount holds optional login details for a provider; an empty username disables login.
+type ProviderAccount struct {
+	Username string
+	Password string
+	LoginURL string
 }
 
 func Load() (Config, error) {
 	_ = godotenv.Load()
 
 	cfg := Config{
-		DBHost:           envOrDefault("DB_HOST", "localhost"),
-		DBPort:           envOrDefault("DB_PORT", "5432"),
-		DBUser:           envOrDefault("DB_USERNAME", "postgres"),
-		DBPassword:       envOrDefault("DB_PASSWORD", "postgres"),
-		DBName:           envOrDefault("DB_DATABASE", "ponisha"),
-		DBSSLMode:        envOrDefault("DB_SSLMODE", "disable"),
-		TelegramToken:    os.Getenv("TELEGRAM_BOT_TOKEN"),
-		TelegramChat:     os.Getenv("TELEGRAM_CHAT_ID"),
-		TelegramThreadID: nil,
-		HTTPPort:         envOrDefault("HTTP_PORT", "3000"),
-		CronSpec:         envOrDefault("SCRAPE_CRON", "*/7 * * * *"),
+		DBHost:             envOrDefault("DB_HOST", "localhost"),
+		DBPort:             envOrDefault("DB_PORT", "5432"),
+		DBUser:             envOrDefault("DB_USERNAME", "postgres"),
+		DBPassword:         envOrDefault("DB_PASSWORD", "postgres"),
+		DBName:             envOrDefault("DB_DATABASE", "ponisha"),
+		DBSSLMode:          envOrDefault("DB_SSLMODE", "disable"),
+		ReplicaID:          envOrDefault("REPLICA_ID", defaultReplicaID()),
+		ScrapeLockScope:    envOrDefault("SCRAPE_LOCK_SCOPE", "source"),
+		TelegramToken:      os.Getenv("TELEGRAM_BOT_TOKEN"),
+		TelegramChat:       os.Getenv("TELEGRAM_CHAT_ID"),
+		TelegramThreadID:   nil,
+		TelegramUpdates:    strings.ToLower(envOrDefault("TELEGRAM_UPDATES", "off")),
+		TelegramWebhookURL: os.Getenv("TELEGRAM_WEBHOOK_URL"),
+		LogFormat:          strings.ToLower(envOrDefault("LOG_FORMAT", "text")),
+		LogLevel:           strings.ToLower(envOrDefault("LOG_LEVEL", "info")),
+		TracingExporter:    strings.ToLower(envOrDefault("TRACING_EXPORTER", "none")),
+		TracingServiceName: envOrDefault("TRACING_SERVICE_NAME", "ponisha-go"),
+		SlackWebhookURL:    os.Getenv("SLACK_WEBHOOK_URL"),
+		DiscordWebhookURL:  os.Getenv("DISCORD_WEBHOOK_URL"),
+		Notifiers:          envList("NOTIFIERS", "telegram"),
+		DigestOrder:        notifiers.DigestOrder(strings.ToLower(envOrDefault("DIGEST_ORDER", "budget"))),
+		HTTPPort:           envOrDefault("HTTP_PORT", "3000"),
+		CronSpec:           envOrDefault("SCRAPE_CRON", "*/7 * * * *"),
+		UserAgent:          envOrDefault("SCRAPER_USER_AGENT", common.DefaultUserAgent),
+		SessionDir:         envOrDefault("SESSION_DIR", ".sessions"),
+		SMTP: SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     envOrDefault("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		},
+		TelegramWebhookSecret: os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
+		Webhook: WebhookConfig{
+			URL:            os.Getenv("WEBHOOK_URL"),
+			Secret:         os.Getenv("WEBHOOK_SECRET"),
+			DeadLetterFile: envOrDefault("WEBHOOK_DEAD_LETTER_FILE", "webhook-dead-letter.jsonl"),
+		},
+		PonishaAccount: ProviderAccount{
+			Username: os.Getenv("PONISHA_USERNAME"),
+			Password: os.Getenv("PONISHA_PASSWORD"),
+			LoginURL: os.Getenv("PONISHA_LOGIN_URL"),
+		},
+		KarlancerAccount: ProviderAccount{
+			Username: os.Getenv("KARLANCER_USERNAME"),
+			Password: os.Getenv("KARLANCER_PASSWORD"),
+			LoginURL: os.Getenv("KARLANCER_LOGIN_URL"),
+		},
 	}
 
 	threadID, err := envOrIntPtr("TELEGRAM_CHAT_THREAD_ID")
@@ -48,19 +208,252 @@ func Load() (Config, error) {
 	}
 	cfg.TelegramThreadID = threadID
 
-	if cfg.TelegramToken == "" || cfg.TelegramChat == "" {
-		return cfg, errors.New("missing TELEGRAM_BOT_TOKEN or TELEGRAM_CHAT_ID")
+	if cfg.TelegramQueueSize, err = envOrInt("TELEGRAM_QUEUE_SIZE", 100); err != nil {
+		return cfg, err
+	}
+	if cfg.TelegramRemindBefore, err = envOrDuration("TELEGRAM_REMIND_BEFORE", 2*time.Hour); err != nil {
+		return cfg, err
+	}
+	if cfg.TelegramAdminIDs, err = envInt64List("TELEGRAM_ADMIN_IDS"); err != nil {
+		return cfg, err
+	}
+	if cfg.BudgetThreshold, err = envOrInt64("BUDGET_THRESHOLD", model.TomanThreshold); err != nil {
+		return cfg, err
+	}
+	if cfg.BudgetThreshold < 0 {
+		return cfg, errors.New("BUDGET_THRESHOLD must not be negative")
+	}
+	if cfg.NotifyQueueSize, err = envOrInt("NOTIFY_QUEUE_SIZE", 100); err != nil {
+		return cfg, err
+	}
+	if cfg.NotifyEnqueueTimeout, err = envOrDuration("NOTIFY_ENQUEUE_TIMEOUT", 30*time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.SMTP.StartTLS, err = envOrBool("SMTP_STARTTLS", true); err != nil {
+		return cfg, err
+	}
+	if cfg.EmailRecipients, err = parseEmailRecipients(os.Getenv("EMAIL_RECIPIENTS")); err != nil {
+		return cfg, err
+	}
+	if cfg.TelegramDigest, err = envDigestSchedule("TELEGRAM_DIGEST"); err != nil {
+		return cfg, err
+	}
+	if cfg.SlackDigest, err = envDigestSchedule("SLACK_DIGEST"); err != nil {
+		return cfg, err
+	}
+	if cfg.DiscordDigest, err = envDigestSchedule("DISCORD_DIGEST"); err != nil {
+		return cfg, err
+	}
+	if cfg.WebhookDigest, err = envDigestSchedule("WEBHOOK_DIGEST"); err != nil {
+		return cfg, err
+	}
+	if cfg.EmailDigest, err = envDigestSchedule("EMAIL_DIGEST"); err != nil {
+		return cfg, err
+	}
+	if cfg.DigestLimit, err = envOrInt("DIGEST_MAX_PROJECTS", notifiers.DefaultDigestLimit); err != nil {
+		return cfg, err
+	}
+	if cfg.Webhook.MaxAttempts, err = envOrInt("WEBHOOK_MAX_ATTEMPTS", 5); err != nil {
+		return cfg, err
+	}
+	if cfg.Webhook.BackoffBase, err = envOrDuration("WEBHOOK_BACKOFF_BASE", time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.Webhook.BackoffMax, err = envOrDuration("WEBHOOK_BACKOFF_MAX", time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.CronJitter, err = envOrDuration("SCRAPE_JITTER", 0); err != nil {
+		return cfg, err
+	}
+	if cfg.ProviderSchedules, err = loadProviderSchedules(); err != nil {
+		return cfg, err
+	}
+	if cfg.ScrapeRunTimeout, err = envOrDuration("SCRAPE_RUN_TIMEOUT", 5*time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.ProviderDownAfter, err = envOrInt("PROVIDER_DOWN_AFTER", 3); err != nil {
+		return cfg, err
+	}
+	if cfg.ProviderBackoffBase, err = envOrDuration("PROVIDER_BACKOFF_BASE", 5*time.Minute); err != nil {
+		return cfg, err
+	}
+	if cfg.ProviderBackoffMax, err = envOrDuration("PROVIDER_BACKOFF_MAX", 2*time.Hour); err != nil {
+		return cfg, err
+	}
+	if cfg.RobotsEnabled, err = envOrBool("ROBOTS_ENABLED", true); err != nil {
+		return cfg, err
+	}
+
+	if len(cfg.Notifiers) == 0 {
+		return cfg, errors.New("NOTIFIERS must name at least one notifier")
+	}
+	for _, name := range cfg.Notifiers {
+		switch name {
+		case "telegram":
+			if cfg.TelegramToken == "" || cfg.TelegramChat == "" {
+				return cfg, errors.New("missing TELEGRAM_BOT_TOKEN or TELEGRAM_CHAT_ID")
+			}
+		case "slack":
+			if cfg.SlackWebhookURL == "" {
+				return cfg, errors.New("missing SLACK_WEBHOOK_URL")
+			}
+		case "discord":
+			if cfg.DiscordWebhookURL == "" {
+				return cfg, errors.New("missing DISCORD_WEBHOOK_URL")
+			}
+		case "email":
+			if cfg.SMTP.Host == "" || cfg.SMTP.From == "" || len(cfg.EmailRecipients) == 0 {
+				return cfg, errors.New("missing SMTP_HOST, SMTP_FROM or EMAIL_RECIPIENTS")
+			}
+		case "webhook":
+			if cfg.Webhook.URL == "" || cfg.Webhook.Secret == "" {
+				return cfg, errors.New("missing WEBHOOK_URL or WEBHOOK_SECRET")
+			}
+		default:
+			return cfg, fmt.Errorf("invalid NOTIFIERS entry: %q", name)
+		}
+	}
+
+	switch cfg.DigestOrder {
+	case notifiers.DigestByBudget, notifiers.DigestByScore:
+	default:
+		return cfg, fmt.Errorf("invalid DIGEST_ORDER: %q", cfg.DigestOrder)
+	}
+	if cfg.DigestLimit < 1 {
+		return cfg, errors.New("DIGEST_MAX_PROJECTS must be positive")
+	}
+
+	switch cfg.TelegramUpdates {
+	case "off":
+	case "poll", "webhook":
+		if cfg.TelegramToken == "" {
+			return cfg, errors.New("TELEGRAM_UPDATES needs TELEGRAM_BOT_TOKEN")
+		}
+		if cfg.TelegramUpdates == "webhook" && cfg.TelegramWebhookURL == "" {
+			return cfg, errors.New("TELEGRAM_UPDATES=webhook needs TELEGRAM_WEBHOOK_URL")
+		}
+		if cfg.TelegramUpdates == "webhook" && !validWebhookSecret(cfg.TelegramWebhookSecret) {
+			return cfg, errors.New("TELEGRAM_UPDATES=webhook needs TELEGRAM_WEBHOOK_SECRET: 1-256 characters of A-Z, a-z, 0-9, _ and -")
+		}
+	default:
+		return cfg, fmt.Errorf("invalid TELEGRAM_UPDATES: %q", cfg.TelegramUpdates)
 	}
 
 	if cfg.DBHost == "" || cfg.DBUser == "" || cfg.DBName == "" {
 		return cfg, errors.New("missing database configuration")
 	}
 
+	switch cfg.LogFormat {
+	case "text", "json":
+	default:
+		return cfg, fmt.Errorf("invalid LOG_FORMAT: %q", cfg.LogFormat)
+	}
+	switch cfg.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		return cfg, fmt.Errorf("invalid LOG_LEVEL: %q", cfg.LogLevel)
+	}
+
+	switch cfg.TracingExporter {
+	case "none", "stdout", "otlp":
+	default:
+		return cfg, fmt.Errorf("invalid TRACING_EXPORTER: %q", cfg.TracingExporter)
+	}
+
+	switch cfg.ScrapeLockScope {
+	case "source", "run", "off":
+	default:
+		return cfg, fmt.Errorf("invalid SCRAPE_LOCK_SCOPE: %q", cfg.ScrapeLockScope)
+	}
+
 	return cfg, nil
 }
 
 func (c Config) PostgresDSN() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName, c.DBSSLMode)
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s", c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName, c.DBSSLMode)
+	if c.ReplicaID != "" {
+		dsn += "&application_name=" + url.QueryEscape(c.ReplicaID)
+	}
+	return dsn
+}
+
+func defaultReplicaID() string {
+	if host, err := os.Hostname(); err == nil && host != "" {
+		return host
+	}
+	return "ponisha-go"
+}
+
+// loadProviderSchedules reads SCRAPE_CRON_<SOURCE> and SCRAPE_JITTER_<SOURCE>. A jitter
+// without a spec of its own applies to the default SCRAPE_CRON.
+func loadProviderSchedules() (map[string]Schedule, error) {
+	schedules := map[string]Schedule{}
+	for _, entry := range os.Environ() {
+		key, value, _ := strings.Cut(entry, "=")
+		if value == "" {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(key, "SCRAPE_CRON_"):
+			source := strings.ToLower(strings.TrimPrefix(key, "SCRAPE_CRON_"))
+			schedule := schedules[source]
+			schedule.Spec = value
+			schedules[source] = schedule
+		case strings.HasPrefix(key, "SCRAPE_JITTER_"):
+			source := strings.ToLower(strings.TrimPrefix(key, "SCRAPE_JITTER_"))
+			jitter, err := time.ParseDuration(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			schedule := schedules[source]
+			schedule.Jitter = jitter
+			schedules[source] = schedule
+		}
+	}
+	return schedules, nil
+}
+
+// parseEmailRecipients reads entries separated by ";", each an address optionally followed by
+// space-separated filters, e.g. "bob@example.com sources=karlancer skills=go,react minBudget=50000000".
+func parseEmailRecipients(value string) ([]EmailRecipient, error) {
+	var recipients []EmailRecipient
+	for _, entry := range strings.Split(value, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		recipient := EmailRecipient{Address: fields[0]}
+		for _, field := range fields[1:] {
+			key, val, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid EMAIL_RECIPIENTS filter %q for %s", field, recipient.Address)
+			}
+			switch strings.ToLower(key) {
+			case "sources":
+				recipient.Sources = splitList(val)
+			case "skills":
+				recipient.Skills = splitList(val)
+			case "keywords":
+				recipient.Keywords = splitList(val)
+			case "minbudget":
+				amount, err := strconv.ParseInt(val, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid EMAIL_RECIPIENTS minBudget for %s: %w", recipient.Address, err)
+				}
+				recipient.MinBudget = amount
+			case "digest":
+				schedule, err := notifiers.ParseDigestSchedule(val)
+				if err != nil {
+					return nil, fmt.Errorf("invalid EMAIL_RECIPIENTS digest for %s: %w", recipient.Address, err)
+				}
+				recipient.Digest = &schedule
+			default:
+				return nil, fmt.Errorf("unknown EMAIL_RECIPIENTS filter %q for %s", key, recipient.Address)
+			}
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
 }
 
 func envOrDefault(key, fallback string) string {
@@ -70,6 +463,21 @@ func envOrDefault(key, fallback string) string {
 	return fallback
 }
 
+// envList reads a comma-separated, lower-cased list, dropping empty entries.
+func envList(key, fallback string) []string {
+	return splitList(envOrDefault(key, fallback))
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func envOrIntPtr(key string) (*int, error) {
 	val := os.Getenv(key)
 	if val == "" {
@@ -81,3 +489,85 @@ func envOrIntPtr(key string) (*int, error) {
 	}
 	return &parsed, nil
 }
+
+func envOrInt(key string, fallback int) (int, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func envDigestSchedule(key string) (notifiers.DigestSchedule, error) {
+	schedule, err := notifiers.ParseDigestSchedule(os.Getenv(key))
+	if err != nil {
+		return schedule, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return schedule, nil
+}
+
+func envOrInt64(key string, fallback int64) (int64, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// envInt64List reads a comma-separated list of integers such as Telegram user IDs.
+func envInt64List(key string) ([]int64, error) {
+	var out []int64
+	for _, item := range splitList(os.Getenv(key)) {
+		parsed, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", key, item, err)
+		}
+		out = append(out, parsed)
+	}
+	return out, nil
+}
+
+func envOrDuration(key string, fallback time.Duration) (time.Duration, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback, nil
+	}
+	parsed, err := time.ParseDuration(val)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func envOrBool(key string, fallback bool) (bool, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// validWebhookSecret reports whether secret is a secret_token Telegram accepts.
+func validWebhookSecret(secret string) bool {
+	if secret == "" || len(secret) > 256 {
+		return false
+	}
+	for _, r := range secret {
+		if !(r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
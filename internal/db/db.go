@@ -5,18 +5,59 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func NewPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
-	pool, err := pgxpool.New(ctx, dsn)
+// NewPool connects to dsn. Every query is passed to tracers in order (used for metrics and
+// tracing).
+func NewPool(ctx context.Context, dsn string, tracers ...pgx.QueryTracer) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if len(tracers) > 0 {
+		config.ConnConfig.Tracer = queryTracers(tracers)
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, err
 	}
 	return pool, nil
 }
 
+type queryTracers []pgx.QueryTracer
+
+func (t queryTracers) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for _, tracer := range t {
+		ctx = tracer.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (t queryTracers) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for _, tracer := range t {
+		tracer.TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+// OperationName reads the name sqlc puts in its "-- name: Foo :one" header, and otherwise
+// falls back to the statement's first keyword so hand-written queries keep a bounded label set.
+func OperationName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name:"); ok {
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+	if fields := strings.Fields(sql); len(fields) > 0 {
+		return strings.ToLower(fields[0])
+	}
+	return "unknown"
+}
+
 func EnsureSchema(ctx context.Context, pool *pgxpool.Pool, basePath string) error {
 	schemaPath := filepath.Join(basePath, "db", "schema.sql")
 	content, err := os.ReadFile(schemaPath)
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AdvisoryLocker coordinates replicas with session-level Postgres advisory locks. Each held
+// lock pins one pooled connection; if the process dies, Postgres drops the lock with it.
+type AdvisoryLocker struct {
+	pool *pgxpool.Pool
+}
+
+func NewAdvisoryLocker(pool *pgxpool.Pool) *AdvisoryLocker {
+	return &AdvisoryLocker{pool: pool}
+}
+
+// TryLock takes the lock for name without waiting. When another session holds it, release
+// is nil and holder is that session's application_name (the replica ID) if it can be read.
+func (l *AdvisoryLocker) TryLock(ctx context.Context, name string) (func(), string, error) {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := lockKey(name)
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, "", err
+	}
+	if !acquired {
+		holder := lockHolder(ctx, conn, key)
+		conn.Release()
+		return nil, holder, nil
+	}
+
+	release := func() {
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(unlockCtx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			slog.WarnContext(ctx, "advisory unlock failed, closing connection", "lock", name, "error", err)
+			_ = conn.Conn().Close(unlockCtx)
+		}
+		conn.Release()
+	}
+	return release, "", nil
+}
+
+func lockHolder(ctx context.Context, conn *pgxpool.Conn, key int64) string {
+	const query = `SELECT a.application_name
+FROM pg_locks l
+JOIN pg_stat_activity a ON a.pid = l.pid
+WHERE l.locktype = 'advisory' AND l.granted
+  AND l.classid::bigint = $1 AND l.objid::bigint = $2 AND l.objsubid = 1
+LIMIT 1`
+
+	var holder string
+	classID := int64(uint32(uint64(key) >> 32))
+	objID := int64(uint32(uint64(key)))
+	if err := conn.QueryRow(ctx, query, classID, objID).Scan(&holder); err != nil {
+		return ""
+	}
+	return holder
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("ponisha-go:" + name))
+	return int64(h.Sum64())
+}
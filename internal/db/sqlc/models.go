@@ -19,3 +19,16 @@ type Project struct {
 	AmountMax  int64
 	CreatedAt  pgtype.Timestamptz
 }
+
+type ProjectTriage struct {
+	Source     string
+	ExternalID string
+	UserID     int64
+	UserName   string
+	State      string
+	ChatID     int64
+	MessageID  int64
+	RemindAt   pgtype.Timestamptz
+	RemindedAt pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
@@ -7,8 +7,80 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const claimDueReminders = `-- name: ClaimDueReminders :many
+UPDATE project_triage t
+SET reminded_at = NOW()
+FROM projects p
+WHERE p.source = t.source
+  AND p.external_id = t.external_id
+  AND (t.source, t.external_id, t.user_id) IN (
+    SELECT d.source, d.external_id, d.user_id
+    FROM project_triage d
+    WHERE d.state = 'remind' AND d.reminded_at IS NULL AND d.remind_at <= $1
+    ORDER BY d.remind_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+  )
+RETURNING t.source, t.external_id, t.user_id, t.user_name, t.state, t.chat_id, t.message_id, t.remind_at, t.reminded_at, t.updated_at, p.title, p.link
+`
+
+type ClaimDueRemindersParams struct {
+	RemindAt pgtype.Timestamptz
+	Limit    int32
+}
+
+type ClaimDueRemindersRow struct {
+	Source     string
+	ExternalID string
+	UserID     int64
+	UserName   string
+	State      string
+	ChatID     int64
+	MessageID  int64
+	RemindAt   pgtype.Timestamptz
+	RemindedAt pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+	Title      string
+	Link       string
+}
+
+func (q *Queries) ClaimDueReminders(ctx context.Context, arg ClaimDueRemindersParams) ([]ClaimDueRemindersRow, error) {
+	rows, err := q.db.Query(ctx, claimDueReminders, arg.RemindAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ClaimDueRemindersRow
+	for rows.Next() {
+		var i ClaimDueRemindersRow
+		if err := rows.Scan(
+			&i.Source,
+			&i.ExternalID,
+			&i.UserID,
+			&i.UserName,
+			&i.State,
+			&i.ChatID,
+			&i.MessageID,
+			&i.RemindAt,
+			&i.RemindedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Link,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createProjectIfNotExists = `-- name: CreateProjectIfNotExists :one
 INSERT INTO projects (
   source,
@@ -86,3 +158,215 @@ func (q *Queries) GetProjectBySourceExternalID(ctx context.Context, arg GetProje
 	)
 	return i, err
 }
+
+const listLatestProjects = `-- name: ListLatestProjects :many
+SELECT id, source, external_id, title, link, budget_text, amount_min, amount_max, created_at
+FROM projects
+ORDER BY created_at DESC, id DESC
+LIMIT $1
+`
+
+func (q *Queries) ListLatestProjects(ctx context.Context, limit int32) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listLatestProjects, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Source,
+			&i.ExternalID,
+			&i.Title,
+			&i.Link,
+			&i.BudgetText,
+			&i.AmountMin,
+			&i.AmountMax,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTriageByProject = `-- name: ListTriageByProject :many
+SELECT source, external_id, user_id, user_name, state, chat_id, message_id, remind_at, reminded_at, updated_at
+FROM project_triage
+WHERE source = $1 AND external_id = $2
+ORDER BY updated_at
+`
+
+type ListTriageByProjectParams struct {
+	Source     string
+	ExternalID string
+}
+
+func (q *Queries) ListTriageByProject(ctx context.Context, arg ListTriageByProjectParams) ([]ProjectTriage, error) {
+	rows, err := q.db.Query(ctx, listTriageByProject, arg.Source, arg.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProjectTriage
+	for rows.Next() {
+		var i ProjectTriage
+		if err := rows.Scan(
+			&i.Source,
+			&i.ExternalID,
+			&i.UserID,
+			&i.UserName,
+			&i.State,
+			&i.ChatID,
+			&i.MessageID,
+			&i.RemindAt,
+			&i.RemindedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const projectStatsBySource = `-- name: ProjectStatsBySource :many
+SELECT
+  source,
+  COUNT(*)::bigint AS total,
+  COUNT(*) FILTER (WHERE created_at >= $1)::bigint AS recent,
+  MAX(created_at)::timestamptz AS last_created_at
+FROM projects
+GROUP BY source
+ORDER BY source
+`
+
+type ProjectStatsBySourceRow struct {
+	Source        string
+	Total         int64
+	Recent        int64
+	LastCreatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) ProjectStatsBySource(ctx context.Context, createdAt pgtype.Timestamptz) ([]ProjectStatsBySourceRow, error) {
+	rows, err := q.db.Query(ctx, projectStatsBySource, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProjectStatsBySourceRow
+	for rows.Next() {
+		var i ProjectStatsBySourceRow
+		if err := rows.Scan(
+			&i.Source,
+			&i.Total,
+			&i.Recent,
+			&i.LastCreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchProjects = `-- name: SearchProjects :many
+SELECT id, source, external_id, title, link, budget_text, amount_min, amount_max, created_at
+FROM projects
+WHERE title ILIKE '%' || $1::text || '%'
+ORDER BY created_at DESC, id DESC
+LIMIT $2
+`
+
+type SearchProjectsParams struct {
+	Query    string
+	RowLimit int32
+}
+
+func (q *Queries) SearchProjects(ctx context.Context, arg SearchProjectsParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, searchProjects, arg.Query, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.Source,
+			&i.ExternalID,
+			&i.Title,
+			&i.Link,
+			&i.BudgetText,
+			&i.AmountMin,
+			&i.AmountMax,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTriage = `-- name: UpsertTriage :exec
+INSERT INTO project_triage (
+  source,
+  external_id,
+  user_id,
+  user_name,
+  state,
+  chat_id,
+  message_id,
+  remind_at
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (source, external_id, user_id) DO UPDATE SET
+  user_name = EXCLUDED.user_name,
+  state = EXCLUDED.state,
+  chat_id = EXCLUDED.chat_id,
+  message_id = EXCLUDED.message_id,
+  remind_at = EXCLUDED.remind_at,
+  reminded_at = NULL,
+  updated_at = NOW()
+`
+
+type UpsertTriageParams struct {
+	Source     string
+	ExternalID string
+	UserID     int64
+	UserName   string
+	State      string
+	ChatID     int64
+	MessageID  int64
+	RemindAt   pgtype.Timestamptz
+}
+
+func (q *Queries) UpsertTriage(ctx context.Context, arg UpsertTriageParams) error {
+	_, err := q.db.Exec(ctx, upsertTriage,
+		arg.Source,
+		arg.ExternalID,
+		arg.UserID,
+		arg.UserName,
+		arg.State,
+		arg.ChatID,
+		arg.MessageID,
+		arg.RemindAt,
+	)
+	return err
+}
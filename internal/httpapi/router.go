@@ -1,10 +1,11 @@
 package httpapi
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/pprof"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 
@@ -12,16 +13,45 @@ import (
 )
 
 type Handler struct {
-	service *scraping.Service
+	service  *scraping.Service
+	metrics  http.Handler
+	telegram http.Handler
 }
 
-func NewHandler(service *scraping.Service) *Handler {
-	return &Handler{service: service}
+type Option func(*Handler)
+
+// WithMetrics serves metrics on /metrics.
+func WithMetrics(metrics http.Handler) Option {
+	return func(h *Handler) {
+		h.metrics = metrics
+	}
+}
+
+// WithTelegramWebhook receives Telegram bot updates on POST /telegram/webhook.
+func WithTelegramWebhook(handler http.Handler) Option {
+	return func(h *Handler) {
+		h.telegram = handler
+	}
+}
+
+func NewHandler(service *scraping.Service, options ...Option) *Handler {
+	h := &Handler{service: service}
+	for _, option := range options {
+		option(h)
+	}
+	return h
 }
 
 func (h *Handler) Router() http.Handler {
 	r := chi.NewRouter()
 	r.Get("/scraping", h.handleScrape)
+	r.Get("/status", h.handleStatus)
+	if h.metrics != nil {
+		r.Handle("/metrics", h.metrics)
+	}
+	if h.telegram != nil {
+		r.Post("/telegram/webhook", h.telegram.ServeHTTP)
+	}
 	r.Route("/debug/pprof", func(r chi.Router) {
 		r.Get("/", pprof.Index)
 		r.Get("/cmdline", pprof.Cmdline)
@@ -39,9 +69,53 @@ func (h *Handler) Router() http.Handler {
 	return r
 }
 
+// handleScrape starts a run in the background, or with ?wait=true runs it synchronously
+// and responds with the run report. Repeated ?source= parameters limit the run to those providers.
+// ?dryRun=true always runs synchronously and writes and sends nothing. Unknown sources get 400,
+// a run already in progress 409 and a shutting-down service 503, before anything is started.
 func (h *Handler) handleScrape(w http.ResponseWriter, r *http.Request) {
-	go h.service.Run(context.Background())
+	w.Header().Set("Content-Type", "application/json")
+	sources := r.URL.Query()["source"]
+	wait, _ := strconv.ParseBool(r.URL.Query().Get("wait"))
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	if !wait && !dryRun {
+		runID, err := h.service.Trigger(sources...)
+		if err != nil {
+			writeRunError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": "Scraping started", "runId": runID})
+		return
+	}
+
+	run := h.service.RunSync
+	if dryRun {
+		run = h.service.DryRun
+	}
+	report, err := run(r.Context(), sources...)
+	if err != nil {
+		writeRunError(w, err)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
+
+func writeRunError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, scraping.ErrUnknownSource):
+		status = http.StatusBadRequest
+	case errors.Is(err, scraping.ErrAlreadyRunning):
+		status = http.StatusConflict
+	case errors.Is(err, scraping.ErrShuttingDown):
+		status = http.StatusServiceUnavailable
+	}
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
 
+func (h *Handler) handleStatus(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(map[string]string{"message": "Scraping started"})
+	_ = json.NewEncoder(w).Encode(h.service.Status())
 }
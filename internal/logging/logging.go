@@ -0,0 +1,117 @@
+// Package logging configures slog and carries log attributes through contexts, so every
+// line logged while handling a run can be filtered by run_id, source and page.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Setup installs a logger writing to stderr as the slog default. The standard log package
+// is routed through it as well.
+func Setup(format, level string) error {
+	logger, err := New(os.Stderr, format, level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// New returns a logger using format ("text" or "json") at level ("debug", "info", "warn"
+// or "error") that adds the attributes stored in each call's context.
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("log level %q: %w", level, err)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "text", "":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("log format %q: want text or json", format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+type attrsKey struct{}
+
+// With returns a context whose log lines carry attrs. An attribute replaces an earlier one
+// with the same key.
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	current := attrsFrom(ctx)
+	merged := make([]slog.Attr, 0, len(current)+len(attrs))
+	for _, attr := range current {
+		if !hasKey(attrs, attr.Key) {
+			merged = append(merged, attr)
+		}
+	}
+	merged = append(merged, attrs...)
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+func WithRun(ctx context.Context, runID string) context.Context {
+	return With(ctx, slog.String("run_id", runID))
+}
+
+func WithSource(ctx context.Context, source string) context.Context {
+	return With(ctx, slog.String("source", source))
+}
+
+func WithPage(ctx context.Context, page int) context.Context {
+	return With(ctx, slog.Int("page", page))
+}
+
+// RunID returns the run ID stored by WithRun, or "" outside a run.
+func RunID(ctx context.Context) string {
+	for _, attr := range attrsFrom(ctx) {
+		if attr.Key == "run_id" {
+			return attr.Value.String()
+		}
+	}
+	return ""
+}
+
+func attrsFrom(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHandler adds the attributes stored by With to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := attrsFrom(ctx); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
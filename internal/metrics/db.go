@@ -0,0 +1,38 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"ponisha-go/internal/db"
+)
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	operation string
+	at        time.Time
+}
+
+// QueryTracer returns a pgx tracer that records the latency of every query.
+func (m *Metrics) QueryTracer() pgx.QueryTracer {
+	return queryTracer{m: m}
+}
+
+type queryTracer struct {
+	m *Metrics
+}
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{operation: db.OperationName(data.SQL), at: time.Now()})
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	t.m.dbDuration.WithLabelValues(start.operation, result(data.Err)).Observe(time.Since(start.at).Seconds())
+}
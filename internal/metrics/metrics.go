@@ -0,0 +1,164 @@
+// Package metrics collects the Prometheus metrics the service exposes on /metrics. Packages
+// that feed it declare the small interface they need, so only this package and the app
+// builder depend on the Prometheus client.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "ponisha"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	runs              *prometheus.CounterVec
+	sourceDuration    *prometheus.HistogramVec
+	pages             *prometheus.CounterVec
+	providerResponses *prometheus.CounterVec
+	projects          *prometheus.CounterVec
+	notifierSend      *prometheus.HistogramVec
+	notifierFailures  *prometheus.CounterVec
+	notifierDrops     *prometheus.CounterVec
+	dbDuration        *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scrape_runs_total",
+			Help:      "Scrape runs by result (ok or error).",
+		}, []string{"result"}),
+		sourceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "scrape_source_duration_seconds",
+			Help:      "Time to scrape one provider, by result (ok, error or skipped).",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+		}, []string{"source", "result"}),
+		pages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scrape_pages_total",
+			Help:      "Listing pages fetched, by result (ok or error).",
+		}, []string{"source", "result"}),
+		providerResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "provider_responses_total",
+			Help:      "HTTP responses from providers by status code; code is \"error\" when no response arrived.",
+		}, []string{"source", "code"}),
+		projects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "scrape_projects_total",
+			Help:      "Projects by outcome: fetched, over_threshold, below_threshold, duplicate or saved.",
+		}, []string{"source", "outcome"}),
+		notifierSend: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "notifier_send_duration_seconds",
+			Help:      "Time to deliver one message, excluding rate-limit waits.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"notifier"}),
+		notifierFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifier_send_failures_total",
+			Help:      "Messages that could not be delivered.",
+		}, []string{"notifier"}),
+		notifierDrops: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifier_dropped_total",
+			Help:      "Alerts a fanned-out channel dropped because its queue was full.",
+		}, []string{"notifier"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Database query latency by operation (the sqlc query name where there is one).",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2, 12),
+		}, []string{"operation", "result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.runs,
+		m.sourceDuration,
+		m.pages,
+		m.providerResponses,
+		m.projects,
+		m.notifierSend,
+		m.notifierFailures,
+		m.notifierDrops,
+		m.dbDuration,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveRun(err error) {
+	m.runs.WithLabelValues(result(err)).Inc()
+}
+
+// ObserveSource records how long one provider took; skipped is set when robots.txt kept
+// it from being scraped.
+func (m *Metrics) ObserveSource(source string, duration time.Duration, skipped bool, err error) {
+	label := result(err)
+	if skipped {
+		label = "skipped"
+	}
+	m.sourceDuration.WithLabelValues(source, label).Observe(duration.Seconds())
+}
+
+func (m *Metrics) ObservePage(source string, err error) {
+	m.pages.WithLabelValues(source, result(err)).Inc()
+}
+
+func (m *Metrics) ObserveProjects(source, outcome string, n int) {
+	if n > 0 {
+		m.projects.WithLabelValues(source, outcome).Add(float64(n))
+	}
+}
+
+func (m *Metrics) ObserveResponse(source string, code int, err error) {
+	label := "error"
+	if err == nil {
+		label = strconv.Itoa(code)
+	}
+	m.providerResponses.WithLabelValues(source, label).Inc()
+}
+
+func (m *Metrics) ObserveSend(notifier string, duration time.Duration, err error) {
+	m.notifierSend.WithLabelValues(notifier).Observe(duration.Seconds())
+	if err != nil {
+		m.notifierFailures.WithLabelValues(notifier).Inc()
+	}
+}
+
+func (m *Metrics) ObserveDrop(notifier string) {
+	m.notifierDrops.WithLabelValues(notifier).Inc()
+}
+
+// WatchQueue exports depth as the notifier's queue depth gauge, read at scrape time.
+func (m *Metrics) WatchQueue(notifier string, depth func() int) {
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "notifier_queue_depth",
+		Help:        "Messages waiting to be sent.",
+		ConstLabels: prometheus.Labels{"notifier": notifier},
+	}, func() float64 { return float64(depth()) }))
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,16 @@
+package model
+
+type SystemAlertKind string
+
+const (
+	AlertLayoutChanged     SystemAlertKind = "layout_changed"
+	AlertProviderDown      SystemAlertKind = "provider_down"
+	AlertProviderRecovered SystemAlertKind = "provider_recovered"
+)
+
+// SystemAlert is an operational message about the scraper itself rather than a project.
+type SystemAlert struct {
+	Kind    SystemAlertKind
+	Source  string
+	Message string
+}
@@ -1,7 +1,8 @@
 package model
 
+// TomanThreshold is the default budget a project must exceed to be saved and alerted.
 const TomanThreshold int64 = 99_000_000
 
-func IsAboveThreshold(amountMin, amountMax int64) bool {
-	return amountMax > TomanThreshold || amountMin > TomanThreshold
+func IsAboveThreshold(amountMin, amountMax, threshold int64) bool {
+	return amountMax > threshold || amountMin > threshold
 }
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Digest is a batch of alerts sent as one summary: the projects saved between From and To,
+// already in the order they should be listed.
+type Digest struct {
+	From     time.Time
+	To       time.Time
+	Projects []ScrapedProject
+}
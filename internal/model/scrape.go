@@ -0,0 +1,103 @@
+package model
+
+import "fmt"
+
+// SkipReason explains why a provider dropped a listing item before it reached the service.
+type SkipReason string
+
+const (
+	SkipMalformedItem     SkipReason = "malformed_item"
+	SkipMissingID         SkipReason = "missing_id"
+	SkipUnparseableBudget SkipReason = "unparseable_budget"
+	// SkipNoBudget is a listing without a price, e.g. a negotiable one. It is not a parse
+	// failure and does not count towards LayoutChanged.
+	SkipNoBudget SkipReason = "no_budget"
+)
+
+// ScrapeDiagnostics describes what a provider saw while parsing its pages.
+type ScrapeDiagnostics struct {
+	Pages               int                `json:"pages"`
+	PagesWithoutPayload int                `json:"pagesWithoutPayload"`
+	ItemsSeen           int                `json:"itemsSeen"`
+	ItemsParsed         int                `json:"itemsParsed"`
+	Skipped             map[SkipReason]int `json:"skipped,omitempty"`
+	// Strategies counts pages per extraction strategy, for providers with more than one parser.
+	Strategies map[string]int `json:"strategies,omitempty"`
+}
+
+func (d *ScrapeDiagnostics) UseStrategy(name string) {
+	if d.Strategies == nil {
+		d.Strategies = map[string]int{}
+	}
+	d.Strategies[name]++
+}
+
+func (d *ScrapeDiagnostics) Skip(reason SkipReason) {
+	if d.Skipped == nil {
+		d.Skipped = map[SkipReason]int{}
+	}
+	d.Skipped[reason]++
+}
+
+func (d *ScrapeDiagnostics) Merge(other ScrapeDiagnostics) {
+	d.Pages += other.Pages
+	d.PagesWithoutPayload += other.PagesWithoutPayload
+	d.ItemsSeen += other.ItemsSeen
+	d.ItemsParsed += other.ItemsParsed
+	for reason, count := range other.Skipped {
+		if d.Skipped == nil {
+			d.Skipped = map[SkipReason]int{}
+		}
+		d.Skipped[reason] += count
+	}
+	for name, count := range other.Strategies {
+		if d.Strategies == nil {
+			d.Strategies = map[string]int{}
+		}
+		d.Strategies[name] += count
+	}
+}
+
+// PayloadFound reports whether every fetched page contained the structure the parser expects.
+func (d ScrapeDiagnostics) PayloadFound() bool {
+	return d.PagesWithoutPayload == 0
+}
+
+// LayoutChanged reports whether pages were fetched successfully but nothing could be parsed
+// because the expected payload was missing or none of the items seen could be read, which
+// almost always means the site changed its markup or payload shape. A listing that is simply
+// empty, or only holds items without a budget, does not count.
+func (d ScrapeDiagnostics) LayoutChanged() bool {
+	return d.Pages > 0 && d.ItemsParsed == 0 && (d.PagesWithoutPayload > 0 || d.ItemsSeen > d.Skipped[SkipNoBudget])
+}
+
+// PageError records a listing page that could not be fetched or parsed. Other pages of the
+// same scrape are still processed.
+type PageError struct {
+	Page int
+	Err  error
+}
+
+func (e PageError) Error() string {
+	return fmt.Sprintf("page %d: %v", e.Page, e.Err)
+}
+
+func (e PageError) Unwrap() error {
+	return e.Err
+}
+
+type ScrapeResult struct {
+	Projects    []ScrapedProject
+	Diagnostics ScrapeDiagnostics
+	PageErrors  []PageError
+}
+
+// ScrapePage is one fetched listing page, emitted by streaming scrapers as soon as it is parsed.
+// A page that failed carries Err and no projects.
+type ScrapePage struct {
+	Source      string
+	Number      int
+	Projects    []ScrapedProject
+	Diagnostics ScrapeDiagnostics
+	Err         error
+}
@@ -13,4 +13,6 @@ type ScrapedProject struct {
 	ApprovedAt      string
 	BiddingClosedAt string
 	BidsCount       *int
+	// Annotations are set by pipeline interceptors, e.g. tags or enrichment results.
+	Annotations map[string]string
 }
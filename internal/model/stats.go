@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// SourceStats counts saved projects for one provider.
+type SourceStats struct {
+	Source string
+	Total  int64
+	// Recent counts projects saved since the time the stats were asked for.
+	Recent        int64
+	LastCreatedAt time.Time
+}
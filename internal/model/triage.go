@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// TriageState is what a user decided about an alerted project.
+type TriageState string
+
+const (
+	TriageInterested TriageState = "interested"
+	TriageDismissed  TriageState = "dismissed"
+	// TriageRemind asks for a reminder before bidding closes.
+	TriageRemind TriageState = "remind"
+)
+
+// Triage is one user's state for one project, and the chat message it was chosen on.
+type Triage struct {
+	Source     string
+	ExternalID string
+	UserID     int64
+	UserName   string
+	State      TriageState
+	ChatID     int64
+	MessageID  int64
+	RemindAt   *time.Time
+	UpdatedAt  time.Time
+
+	// Title and Link come from the saved project; they are only set on claimed reminders.
+	Title string
+	Link  string
+}
@@ -0,0 +1,199 @@
+// Package composite fans alerts out to several notifiers. Each child sits behind its own
+// queue, so a slow or failing channel delays and fails only itself: when a child's queue is
+// full the alert is dropped for that child, never waited for.
+package composite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+// Child is one notifier and the name it is logged under.
+type Child struct {
+	Name     string
+	Notifier notifiers.Notifier
+}
+
+type Composite struct {
+	children  []*child
+	queueSize int
+	metrics   Metrics
+}
+
+// Metrics counts alerts a child dropped because its queue was full.
+type Metrics interface {
+	ObserveDrop(notifier string)
+}
+
+type child struct {
+	Child
+	queue *notifiers.Queue[job]
+}
+
+// job hands one alert to a child; delivery completes with the child's own Delivery.
+type job struct {
+	send     func(context.Context) (*notifiers.Delivery, error)
+	delivery *notifiers.Delivery
+}
+
+type Option func(*Composite)
+
+// WithQueueSize sets how many alerts may wait for each child (100 by default).
+func WithQueueSize(size int) Option {
+	return func(c *Composite) {
+		c.queueSize = size
+	}
+}
+
+// WithMetrics reports dropped alerts to m.
+func WithMetrics(m Metrics) Option {
+	return func(c *Composite) {
+		c.metrics = m
+	}
+}
+
+func New(children []Child, options ...Option) *Composite {
+	c := &Composite{queueSize: 100}
+	for _, option := range options {
+		option(c)
+	}
+	for _, ch := range children {
+		c.children = append(c.children, &child{
+			Child: ch,
+			queue: notifiers.NewQueue(c.queueSize, forward),
+		})
+	}
+	return c
+}
+
+// forward hands an alert to the child. It waits while the child has no room for it, so the
+// child's queue drains as fast as the child accepts alerts, but not until the alert has been
+// delivered: a digest child only delivers when its window closes.
+func forward(ctx context.Context, j job) error {
+	delivery, err := j.send(ctx)
+	if err != nil {
+		j.delivery.Complete(err)
+		return err
+	}
+	go func() {
+		<-delivery.Done()
+		j.delivery.Complete(delivery.Err())
+	}()
+	return nil
+}
+
+// SendAlert queues the alert for every child without waiting: a child whose queue is full
+// drops it, which is logged and counted. It fails only when no child accepted the alert. The
+// Delivery succeeds when at least one child delivered it; failures of the others are logged.
+func (c *Composite) SendAlert(ctx context.Context, project model.ScrapedProject) (*notifiers.Delivery, error) {
+	return c.fanOut(ctx, func(n notifiers.Notifier) func(context.Context) (*notifiers.Delivery, error) {
+		return func(ctx context.Context) (*notifiers.Delivery, error) {
+			return n.SendAlert(ctx, project)
+		}
+	})
+}
+
+func (c *Composite) SendSystemAlert(ctx context.Context, alert model.SystemAlert) (*notifiers.Delivery, error) {
+	return c.fanOut(ctx, func(n notifiers.Notifier) func(context.Context) (*notifiers.Delivery, error) {
+		return func(ctx context.Context) (*notifiers.Delivery, error) {
+			return n.SendSystemAlert(ctx, alert)
+		}
+	})
+}
+
+func (c *Composite) fanOut(ctx context.Context, send func(notifiers.Notifier) func(context.Context) (*notifiers.Delivery, error)) (*notifiers.Delivery, error) {
+	var queued []*child
+	var queuedDeliveries []*notifiers.Delivery
+	var errs []error
+	for _, ch := range c.children {
+		j := job{send: send(ch.Notifier), delivery: notifiers.NewDelivery()}
+		if _, err := ch.queue.TryEnqueue(ctx, j); err != nil {
+			slog.WarnContext(ctx, "alert dropped", "notifier", ch.Name, "error", err)
+			if c.metrics != nil {
+				c.metrics.ObserveDrop(ch.Name)
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name, err))
+			continue
+		}
+		queued = append(queued, ch)
+		queuedDeliveries = append(queuedDeliveries, j.delivery)
+	}
+	if len(queued) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	// The combined Delivery completes at the first success, so a slow child does not hold it
+	// up; the remaining outcomes are still collected and logged.
+	combined := notifiers.NewDelivery()
+	logCtx := context.WithoutCancel(ctx)
+	results := make(chan error, len(queuedDeliveries))
+	for i, delivery := range queuedDeliveries {
+		go func() {
+			<-delivery.Done()
+			err := delivery.Err()
+			if err != nil {
+				slog.ErrorContext(logCtx, "alert delivery failed", "notifier", queued[i].Name, "error", err)
+				err = fmt.Errorf("%s: %w", queued[i].Name, err)
+			}
+			results <- err
+		}()
+	}
+	go func() {
+		var failures []error
+		for range queuedDeliveries {
+			err := <-results
+			if err == nil {
+				combined.Complete(nil)
+				return
+			}
+			failures = append(failures, err)
+		}
+		combined.Complete(errors.Join(failures...))
+	}()
+	return combined, nil
+}
+
+// RenderAlert uses the first child that can render alerts.
+func (c *Composite) RenderAlert(project model.ScrapedProject) string {
+	for _, ch := range c.children {
+		if renderer, ok := ch.Notifier.(interface {
+			RenderAlert(model.ScrapedProject) string
+		}); ok {
+			return renderer.RenderAlert(project)
+		}
+	}
+	return fmt.Sprintf("%s\n%s\n%s", project.Title, project.BudgetText, project.Link)
+}
+
+// QueueDepth is the number of alerts waiting for the named child.
+func (c *Composite) QueueDepth(name string) int {
+	for _, ch := range c.children {
+		if ch.Name == name {
+			return ch.queue.Len()
+		}
+	}
+	return 0
+}
+
+// Close drains every child's queue and then closes the children that can be closed.
+func (c *Composite) Close(ctx context.Context) error {
+	var errs []error
+	for _, ch := range c.children {
+		if err := ch.queue.Close(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name, err))
+		}
+	}
+	for _, ch := range c.children {
+		if closer, ok := ch.Notifier.(interface{ Close(context.Context) error }); ok {
+			if err := closer.Close(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ch.Name, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+// Package notifiers holds what every alert channel shares: the Delivery handle returned when
+// an alert is queued, the bounded Queue that sends them and the Digest that batches them into
+// periodic summaries. Channels live in the packages below it (and in internal/telegram).
+package notifiers
+
+import (
+	"context"
+	"errors"
+
+	"ponisha-go/internal/model"
+)
+
+var (
+	// ErrQueueFull is returned when a queue stayed full until the caller's context was done,
+	// or at once by TryEnqueue.
+	ErrQueueFull = errors.New("notification queue full")
+	// ErrClosed is returned for messages offered after the notifier was closed.
+	ErrClosed = errors.New("notifier closed")
+)
+
+// Delivery reports the outcome of one queued message. Queueing and delivering are separate
+// steps: a Delivery exists once a message is queued, and Done is closed when it has been
+// sent or has finally failed.
+type Delivery struct {
+	done chan struct{}
+	err  error
+}
+
+func NewDelivery() *Delivery {
+	return &Delivery{done: make(chan struct{})}
+}
+
+// Delivered returns a Delivery that has already finished with err.
+func Delivered(err error) *Delivery {
+	d := NewDelivery()
+	d.Complete(err)
+	return d
+}
+
+// Complete records the outcome; it must be called exactly once.
+func (d *Delivery) Complete(err error) {
+	d.err = err
+	close(d.done)
+}
+
+func (d *Delivery) Done() <-chan struct{} {
+	return d.done
+}
+
+// Err is the delivery error; it is only meaningful once Done is closed.
+func (d *Delivery) Err() error {
+	select {
+	case <-d.done:
+		return d.err
+	default:
+		return nil
+	}
+}
+
+// Wait blocks until the message has been delivered or ctx is done.
+func (d *Delivery) Wait(ctx context.Context) error {
+	select {
+	case <-d.done:
+		return d.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// All returns a Delivery that completes once every one of deliveries has, failing with
+// their joined errors.
+func All(deliveries ...*Delivery) *Delivery {
+	all := NewDelivery()
+	go func() {
+		var errs []error
+		for _, d := range deliveries {
+			<-d.Done()
+			errs = append(errs, d.Err())
+		}
+		all.Complete(errors.Join(errs...))
+	}()
+	return all
+}
+
+// Notifier is the interface every alert channel implements; it matches scraping.Notifier.
+type Notifier interface {
+	SendAlert(ctx context.Context, project model.ScrapedProject) (*Delivery, error)
+	SendSystemAlert(ctx context.Context, alert model.SystemAlert) (*Delivery, error)
+}
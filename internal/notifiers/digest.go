@@ -0,0 +1,393 @@
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+// DigestOrder is how projects are listed in a digest.
+type DigestOrder string
+
+const (
+	// DigestByBudget lists the largest budgets first.
+	DigestByBudget DigestOrder = "budget"
+	// DigestByScore lists the highest scores first; see DigestScore.
+	DigestByScore DigestOrder = "score"
+)
+
+// ErrDigestFull completes the Delivery of a project that did not fit in a full digest.
+var ErrDigestFull = errors.New("digest full")
+
+// DefaultDigestLimit is how many projects a digest holds unless WithDigestLimit says otherwise.
+const DefaultDigestLimit = 500
+
+// Digest collects projects and hands them to flush as one model.Digest whenever the
+// schedule comes round. Windows without projects are skipped; a digest that fails to send
+// is kept and retried with the next window.
+type Digest struct {
+	schedule DigestSchedule
+	order    DigestOrder
+	limit    int
+	flush    func(ctx context.Context, digest model.Digest) error
+
+	mu      sync.Mutex
+	entries []digestEntry
+	since   time.Time
+	closed  bool
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+type digestEntry struct {
+	project  model.ScrapedProject
+	delivery *Delivery
+}
+
+type DigestOption func(*Digest)
+
+// WithDigestLimit caps how many projects wait for the next digest (DefaultDigestLimit by
+// default). Projects beyond it are dropped and logged.
+func WithDigestLimit(limit int) DigestOption {
+	return func(d *Digest) {
+		d.limit = limit
+	}
+}
+
+// NewDigest starts collecting for schedule, which must be enabled. flush runs on the digest's
+// own goroutine and should return once the digest has been delivered or has failed.
+func NewDigest(schedule DigestSchedule, order DigestOrder, flush func(ctx context.Context, digest model.Digest) error, options ...DigestOption) *Digest {
+	d := &Digest{
+		schedule: schedule,
+		order:    order,
+		limit:    DefaultDigestLimit,
+		flush:    flush,
+		since:    time.Now(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	for _, option := range options {
+		option(d)
+	}
+	go d.run()
+	return d
+}
+
+// Add puts project in the next digest. The returned Delivery completes when a digest
+// listing the project has been sent, or fails with ErrDigestFull when there is no room.
+func (d *Digest) Add(project model.ScrapedProject) *Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return Delivered(ErrClosed)
+	}
+	if len(d.entries) >= d.limit {
+		d.drop(project)
+		return Delivered(ErrDigestFull)
+	}
+	entry := digestEntry{project: project, delivery: NewDelivery()}
+	d.entries = append(d.entries, entry)
+	return entry.delivery
+}
+
+// Len is the number of projects waiting for the next digest.
+func (d *Digest) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.entries)
+}
+
+// Close stops the schedule and sends what has been collected so far, so a restart does not
+// lose the current window. Projects that still could not be sent fail with the error.
+func (d *Digest) Close(ctx context.Context) error {
+	d.stopOnce.Do(func() { close(d.stop) })
+	var err error
+	select {
+	case <-d.done:
+		err = d.send(ctx, time.Now())
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	d.mu.Lock()
+	d.closed = true
+	entries := d.entries
+	d.entries = nil
+	d.mu.Unlock()
+	for _, entry := range entries {
+		entry.delivery.Complete(err)
+	}
+	return err
+}
+
+func (d *Digest) run() {
+	defer close(d.done)
+	for {
+		timer := time.NewTimer(time.Until(d.schedule.Next(time.Now())))
+		select {
+		case now := <-timer.C:
+			ctx := context.Background()
+			if err := d.send(ctx, now); err != nil {
+				slog.ErrorContext(ctx, "digest send failed; kept for the next window", "schedule", d.schedule.String(), "error", err)
+			}
+		case <-d.stop:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// send flushes the projects collected up to now. When flush fails the projects go back in
+// front of those collected meanwhile, still within the limit, and the error is returned.
+func (d *Digest) send(ctx context.Context, now time.Time) error {
+	d.mu.Lock()
+	entries, since := d.entries, d.since
+	d.entries, d.since = nil, now
+	d.mu.Unlock()
+
+	if len(entries) == 0 {
+		return nil
+	}
+	sortDigest(entries, d.order)
+	projects := make([]model.ScrapedProject, len(entries))
+	for i, entry := range entries {
+		projects[i] = entry.project
+	}
+	slog.InfoContext(ctx, "sending digest", "schedule", d.schedule.String(), "projects", len(projects))
+	if err := d.flush(ctx, model.Digest{From: since, To: now, Projects: projects}); err != nil {
+		d.requeue(unsent(entries, err), since)
+		return err
+	}
+	for _, entry := range entries {
+		entry.delivery.Complete(nil)
+	}
+	return nil
+}
+
+// unsent completes the entries a *PartialDigestError reports as sent and returns the rest;
+// after any other error nothing went out.
+func unsent(entries []digestEntry, err error) []digestEntry {
+	var partial *PartialDigestError
+	if !errors.As(err, &partial) {
+		return entries
+	}
+	failed := map[int]bool{}
+	for _, i := range partial.Unsent {
+		failed[i] = true
+	}
+	var rest []digestEntry
+	for i, entry := range entries {
+		if failed[i] {
+			rest = append(rest, entry)
+			continue
+		}
+		entry.delivery.Complete(nil)
+	}
+	return rest
+}
+
+func (d *Digest) requeue(failed []digestEntry, since time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.entries = append(failed, d.entries...)
+	d.since = since
+	if len(d.entries) <= d.limit {
+		return
+	}
+	for _, entry := range d.entries[d.limit:] {
+		d.drop(entry.project)
+		entry.delivery.Complete(ErrDigestFull)
+	}
+	d.entries = d.entries[:d.limit]
+}
+
+func (d *Digest) drop(project model.ScrapedProject) {
+	slog.Warn("digest full; project dropped",
+		"schedule", d.schedule.String(), "limit", d.limit, "source", project.Source,
+		"external_id", project.ExternalID, "title", project.Title, "link", project.Link,
+	)
+}
+
+// DigestSender is a channel that can deliver a whole digest as well as single alerts.
+// Channels that split a digest into several messages complete the Delivery through
+// DigestParts, so a failed message does not make the others go out again.
+type DigestSender interface {
+	Notifier
+	SendDigest(ctx context.Context, digest model.Digest) (*Delivery, error)
+}
+
+// DigestPart is one message of a digest sent as several: it lists the next Projects
+// projects of the digest, in order, and Delivery completes when it has been sent.
+type DigestPart struct {
+	Projects int
+	Delivery *Delivery
+}
+
+// PartialDigestError fails a digest some of whose messages were not sent. Unsent holds the
+// indexes, into the digest's projects, of the projects those messages listed.
+type PartialDigestError struct {
+	Unsent []int
+	Err    error
+}
+
+func (e *PartialDigestError) Error() string {
+	return fmt.Sprintf("digest partly sent, %d projects not: %v", len(e.Unsent), e.Err)
+}
+
+func (e *PartialDigestError) Unwrap() error {
+	return e.Err
+}
+
+// QueueDigest queues a digest sent as several messages, where projects[i] is how many
+// projects messages[i] lists, and combines the deliveries with DigestParts. If a message
+// cannot be queued after earlier ones were, it and the rest fail in the Delivery instead,
+// so only their projects are kept for the next window.
+func QueueDigest[M any](ctx context.Context, messages []M, projects []int, enqueue func(context.Context, M) (*Delivery, error)) (*Delivery, error) {
+	parts := make([]DigestPart, 0, len(messages))
+	for i, msg := range messages {
+		delivery, err := enqueue(ctx, msg)
+		if err != nil && i == 0 {
+			return nil, err
+		}
+		if err != nil {
+			rest := 0
+			for _, n := range projects[i:] {
+				rest += n
+			}
+			parts = append(parts, DigestPart{Projects: rest, Delivery: Delivered(err)})
+			break
+		}
+		parts = append(parts, DigestPart{Projects: projects[i], Delivery: delivery})
+	}
+	return DigestParts(parts...), nil
+}
+
+// DigestParts returns a Delivery that completes once every part has. If any part fails it
+// fails with a *PartialDigestError, so the Digest keeps only that part's projects.
+func DigestParts(parts ...DigestPart) *Delivery {
+	all := NewDelivery()
+	go func() {
+		var errs []error
+		var failed []int
+		start := 0
+		for _, part := range parts {
+			<-part.Delivery.Done()
+			if err := part.Delivery.Err(); err != nil {
+				errs = append(errs, err)
+				for i := start; i < start+part.Projects; i++ {
+					failed = append(failed, i)
+				}
+			}
+			start += part.Projects
+		}
+		if len(errs) == 0 {
+			all.Complete(nil)
+			return
+		}
+		all.Complete(&PartialDigestError{Unsent: failed, Err: errors.Join(errs...)})
+	}()
+	return all
+}
+
+// DigestNotifier puts a channel into digest mode: project alerts are collected in a Digest
+// and handed to the channel's SendDigest once per window, while system alerts go straight
+// through.
+type DigestNotifier struct {
+	sender DigestSender
+	digest *Digest
+}
+
+func NewDigestNotifier(sender DigestSender, schedule DigestSchedule, order DigestOrder, options ...DigestOption) *DigestNotifier {
+	n := &DigestNotifier{sender: sender}
+	n.digest = NewDigest(schedule, order, n.flush, options...)
+	return n
+}
+
+// SendAlert adds the project to the next digest. The Delivery completes when that digest
+// has been sent.
+func (n *DigestNotifier) SendAlert(_ context.Context, project model.ScrapedProject) (*Delivery, error) {
+	return n.digest.Add(project), nil
+}
+
+func (n *DigestNotifier) SendSystemAlert(ctx context.Context, alert model.SystemAlert) (*Delivery, error) {
+	return n.sender.SendSystemAlert(ctx, alert)
+}
+
+// RenderAlert renders the single alert the channel would send, for dry runs.
+func (n *DigestNotifier) RenderAlert(project model.ScrapedProject) string {
+	if renderer, ok := n.sender.(interface {
+		RenderAlert(model.ScrapedProject) string
+	}); ok {
+		return renderer.RenderAlert(project)
+	}
+	return fmt.Sprintf("%s\n%s\n%s", project.Title, project.BudgetText, project.Link)
+}
+
+// Close sends the projects collected so far and then closes the channel.
+func (n *DigestNotifier) Close(ctx context.Context) error {
+	err := n.digest.Close(ctx)
+	if closer, ok := n.sender.(interface{ Close(context.Context) error }); ok {
+		err = errors.Join(err, closer.Close(ctx))
+	}
+	return err
+}
+
+func (n *DigestNotifier) flush(ctx context.Context, digest model.Digest) error {
+	delivery, err := n.sender.SendDigest(ctx, digest)
+	if err != nil {
+		return err
+	}
+	return delivery.Wait(ctx)
+}
+
+// DigestScore ranks a project for DigestByScore. A numeric "score" annotation set by a
+// pipeline interceptor wins; otherwise it is the top of the budget divided by the number of
+// bids plus one, favouring well-paid projects with little competition.
+func DigestScore(project model.ScrapedProject) float64 {
+	if value, ok := project.Annotations["score"]; ok {
+		if score, err := strconv.ParseFloat(value, 64); err == nil {
+			return score
+		}
+	}
+	bids := 0
+	if project.BidsCount != nil {
+		bids = *project.BidsCount
+	}
+	return float64(budget(project)) / float64(bids+1)
+}
+
+func sortDigest(entries []digestEntry, order DigestOrder) {
+	slices.SortStableFunc(entries, func(x, y digestEntry) int {
+		a, b := x.project, y.project
+		if order == DigestByScore {
+			if c := compareDesc(DigestScore(a), DigestScore(b)); c != 0 {
+				return c
+			}
+		}
+		return compareDesc(budget(a), budget(b))
+	})
+}
+
+func compareDesc[T int64 | float64](a, b T) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func budget(project model.ScrapedProject) int64 {
+	return max(project.AmountMin, project.AmountMax)
+}
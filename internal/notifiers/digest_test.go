@@ -0,0 +1,125 @@
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+func TestDigestKeepsFailedBatchForNextWindow(t *testing.T) {
+	var sent []model.Digest
+	fail := true
+	d := NewDigest(DigestSchedule{Period: DigestDaily}, DigestByBudget, func(_ context.Context, digest model.Digest) error {
+		if fail {
+			return errors.New("send failed")
+		}
+		sent = append(sent, digest)
+		return nil
+	}, WithDigestLimit(3))
+	defer d.Close(context.Background())
+
+	first := d.Add(model.ScrapedProject{ExternalID: "a", AmountMax: 1})
+	second := d.Add(model.ScrapedProject{ExternalID: "b", AmountMax: 2})
+	start := d.since
+	window := start.Add(time.Hour)
+	if err := d.send(context.Background(), window); err == nil {
+		t.Fatal("send succeeded, want the flush error")
+	}
+	select {
+	case <-first.Done():
+		t.Fatal("delivery completed although the digest was not sent")
+	default:
+	}
+	if d.Len() != 2 {
+		t.Fatalf("kept %d projects, want 2", d.Len())
+	}
+
+	// The retried batch and one newer project fill the limit; the next one is dropped.
+	third := d.Add(model.ScrapedProject{ExternalID: "c", AmountMax: 3})
+	if dropped := d.Add(model.ScrapedProject{ExternalID: "d"}); !errors.Is(dropped.Err(), ErrDigestFull) {
+		t.Fatalf("delivery of a project over the limit = %v, want ErrDigestFull", dropped.Err())
+	}
+
+	fail = false
+	if err := d.send(context.Background(), window.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || len(sent[0].Projects) != 3 {
+		t.Fatalf("sent %+v, want one digest of 3 projects", sent)
+	}
+	if !sent[0].From.Equal(start) {
+		t.Errorf("digest starts at %v, want the failed window's start %v", sent[0].From, start)
+	}
+	if got := sent[0].Projects[0].ExternalID; got != "c" {
+		t.Errorf("first project %q, want the largest budget c", got)
+	}
+	for _, delivery := range []*Delivery{first, second, third} {
+		if err := delivery.Wait(context.Background()); err != nil {
+			t.Errorf("delivery failed: %v", err)
+		}
+	}
+}
+
+func TestDigestResendsOnlyUnsentParts(t *testing.T) {
+	var sent []model.Digest
+	attempt := 0
+	d := NewDigest(DigestSchedule{Period: DigestDaily}, DigestByBudget, func(_ context.Context, digest model.Digest) error {
+		attempt++
+		sent = append(sent, digest)
+		if attempt > 1 {
+			return nil
+		}
+		// Three messages of two projects each; the second is rejected.
+		return DigestParts(
+			DigestPart{Projects: 2, Delivery: Delivered(nil)},
+			DigestPart{Projects: 2, Delivery: Delivered(errors.New("rate limited"))},
+			DigestPart{Projects: 2, Delivery: Delivered(nil)},
+		).Wait(context.Background())
+	})
+	defer d.Close(context.Background())
+
+	deliveries := map[string]*Delivery{}
+	for i, id := range []string{"a", "b", "c", "d", "e", "f"} {
+		deliveries[id] = d.Add(model.ScrapedProject{ExternalID: id, AmountMax: int64(60 - i)})
+	}
+	window := d.since.Add(time.Hour)
+	err := d.send(context.Background(), window)
+	var partial *PartialDigestError
+	if !errors.As(err, &partial) {
+		t.Fatalf("send error = %v, want a *PartialDigestError", err)
+	}
+
+	for id, delivery := range deliveries {
+		select {
+		case <-delivery.Done():
+			if id == "c" || id == "d" {
+				t.Errorf("delivery of %s completed although its message failed", id)
+			} else if delivery.Err() != nil {
+				t.Errorf("delivery of %s failed: %v", id, delivery.Err())
+			}
+		default:
+			if id != "c" && id != "d" {
+				t.Errorf("delivery of %s still pending although its message was sent", id)
+			}
+		}
+	}
+
+	if err := d.send(context.Background(), window.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	var resent []string
+	for _, project := range sent[1].Projects {
+		resent = append(resent, project.ExternalID)
+	}
+	if len(resent) != 2 || resent[0] != "c" || resent[1] != "d" {
+		t.Errorf("resent %v, want only c and d", resent)
+	}
+	for _, id := range []string{"c", "d"} {
+		if err := deliveries[id].Wait(context.Background()); err != nil {
+			t.Errorf("delivery of %s failed: %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+
+	"ponisha-go/internal/model"
+)
+
+const (
+	// Titles and links are capped so a single line always fits in one embed.
+	maxDigestTitle = 120
+	maxDigestLink  = 2000
+	digestColor    = 0x9B51E0
+)
+
+// digestMessages renders the digest as one line per project in the description of one embed
+// per message, using as many messages as the description limit needs. It also returns how
+// many projects each message lists.
+func digestMessages(digest model.Digest) ([]message, []int) {
+	title := fmt.Sprintf("Project digest: %d projects", len(digest.Projects))
+
+	var chunks []string
+	var projects []int
+	var current strings.Builder
+	size, count := 0, 0
+	for i, project := range digest.Projects {
+		line := digestLine(i+1, project)
+		length := len([]rune(line))
+		if size > 0 && size+1+length > maxDescription {
+			chunks = append(chunks, current.String())
+			projects = append(projects, count)
+			current.Reset()
+			size, count = 0, 0
+		}
+		if size > 0 {
+			current.WriteString("\n")
+			size++
+		}
+		current.WriteString(line)
+		size += length
+		count++
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+		projects = append(projects, count)
+	}
+
+	messages := make([]message, len(chunks))
+	for i, chunk := range chunks {
+		e := embed{
+			Title:       title,
+			Description: chunk,
+			Color:       digestColor,
+			Footer:      &footer{Text: fmt.Sprintf("%s to %s", digest.From.UTC().Format("2006-01-02 15:04"), digest.To.UTC().Format("2006-01-02 15:04 UTC"))},
+			Timestamp:   digest.To.UTC().Format("2006-01-02T15:04:05Z"),
+		}
+		if len(chunks) > 1 {
+			e.Title = fmt.Sprintf("%s (%d/%d)", title, i+1, len(chunks))
+		}
+		messages[i] = message{Embeds: []embed{fitEmbed(e)}}
+	}
+	return messages, projects
+}
+
+func digestLine(n int, project model.ScrapedProject) string {
+	title := truncate(project.Title, maxDigestTitle)
+	if project.Link != "" && len(project.Link) <= maxDigestLink {
+		title = fmt.Sprintf("[%s](%s)", title, project.Link)
+	}
+	budget := project.BudgetText
+	if budget == "" {
+		budget = "—"
+	}
+	line := fmt.Sprintf("%d. **%s** — %s · %s", n, title, truncate(budget, maxDigestTitle), project.Source)
+	if project.BidsCount != nil {
+		line += fmt.Sprintf(" · %d bids", *project.BidsCount)
+	}
+	return line
+}
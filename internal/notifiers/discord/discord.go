@@ -0,0 +1,196 @@
+// Package discord delivers alerts to a Discord channel through a webhook, rendered as embeds.
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+	"ponisha-go/internal/tracing"
+)
+
+// Notifier posts alerts to one webhook URL.
+type Notifier struct {
+	webhookURL string
+
+	client    *http.Client
+	queue     *notifiers.Queue[message]
+	queueSize int
+	throttle  *notifiers.Throttle
+
+	metrics Metrics
+}
+
+// Metrics receives the latency and outcome of every delivered message.
+type Metrics interface {
+	ObserveSend(notifier string, duration time.Duration, err error)
+}
+
+type Option func(*Notifier)
+
+func WithMetrics(metrics Metrics) Option {
+	return func(n *Notifier) {
+		n.metrics = metrics
+	}
+}
+
+// WithHTTPClient replaces the default client used to call the webhook.
+func WithHTTPClient(client *http.Client) Option {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// WithQueueSize sets how many messages may wait to be sent (100 by default).
+func WithQueueSize(size int) Option {
+	return func(n *Notifier) {
+		n.queueSize = size
+	}
+}
+
+// New returns a Notifier posting to webhookURL.
+func New(webhookURL string, options ...Option) *Notifier {
+	n := &Notifier{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 15 * time.Second},
+		queueSize:  100,
+	}
+	for _, option := range options {
+		option(n)
+	}
+	// Webhooks get about five requests per two seconds; the bucket headers do the rest.
+	n.throttle = &notifiers.Throttle{MinInterval: 400 * time.Millisecond, Observe: n.observe}
+
+	n.queue = notifiers.NewQueue(n.queueSize, n.send)
+	return n
+}
+
+// SendAlert queues the alert, waiting for room in the queue until ctx is done. The returned
+// Delivery completes once Discord has accepted the message.
+func (n *Notifier) SendAlert(ctx context.Context, project model.ScrapedProject) (*notifiers.Delivery, error) {
+	return n.enqueue(ctx, projectMessage(project))
+}
+
+// SendDigest queues the digest as one message, or several when it has more lines than an
+// embed can hold. The Delivery completes once Discord has accepted all of them; the
+// projects of any message it did not accept are kept for the next digest.
+func (n *Notifier) SendDigest(ctx context.Context, digest model.Digest) (*notifiers.Delivery, error) {
+	messages, projects := digestMessages(digest)
+	return notifiers.QueueDigest(ctx, messages, projects, n.enqueue)
+}
+
+func (n *Notifier) RenderAlert(project model.ScrapedProject) string {
+	embed := projectEmbed(project)
+	return embed.Title + "\n" + embed.URL
+}
+
+func (n *Notifier) SendSystemAlert(ctx context.Context, alert model.SystemAlert) (*notifiers.Delivery, error) {
+	return n.enqueue(ctx, systemAlertMessage(alert))
+}
+
+// QueueDepth is the number of messages waiting to be sent.
+func (n *Notifier) QueueDepth() int {
+	return n.queue.Len()
+}
+
+// Close stops accepting alerts and waits until the queued messages have been sent.
+func (n *Notifier) Close(ctx context.Context) error {
+	return n.queue.Close(ctx)
+}
+
+func (n *Notifier) enqueue(ctx context.Context, msg message) (*notifiers.Delivery, error) {
+	delivery, err := n.queue.Enqueue(ctx, msg)
+	if err != nil {
+		slog.WarnContext(ctx, "discord message not queued", "error", err)
+		return nil, fmt.Errorf("discord: %w", err)
+	}
+	return delivery, nil
+}
+
+func (n *Notifier) send(ctx context.Context, msg message) error {
+	retried, err := n.throttle.Do(ctx, func(ctx context.Context) (time.Duration, error) {
+		retryAfter, err := n.post(ctx, msg)
+		if retryAfter > 0 {
+			slog.WarnContext(ctx, "discord rate limit hit; retrying", "retry_after", retryAfter)
+		}
+		return retryAfter, err
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "discord send error", "error", err, "retried", retried)
+		return err
+	}
+	slog.InfoContext(ctx, "discord alert sent", "retried", retried)
+	return nil
+}
+
+func (n *Notifier) observe(duration time.Duration, err error) {
+	if n.metrics != nil {
+		n.metrics.ObserveSend("discord", duration, err)
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, msg message) (retryAfter time.Duration, err error) {
+	ctx, span := tracing.Start(ctx, "discord.executeWebhook")
+	defer func() { tracing.End(span, err) }()
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	n.holdForBucket(resp.Header)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		var limited rateLimitResponse
+		_ = json.Unmarshal(reply, &limited)
+		wait := time.Duration(limited.RetryAfter * float64(time.Second))
+		if wait <= 0 {
+			wait = notifiers.RetryAfter(resp.Header.Get("Retry-After"), time.Second)
+		}
+		return wait, fmt.Errorf("rate limited (global=%t)", limited.Global)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("discord error: %d %s", resp.StatusCode, strings.TrimSpace(string(reply)))
+	}
+	return 0, nil
+}
+
+// holdForBucket pauses sending once the webhook's rate limit bucket is exhausted, so the next
+// message waits for the bucket to reset instead of drawing a 429.
+func (n *Notifier) holdForBucket(header http.Header) {
+	if header.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	if seconds, err := strconv.ParseFloat(header.Get("X-RateLimit-Reset-After"), 64); err == nil && seconds > 0 {
+		n.throttle.Hold(time.Duration(seconds * float64(time.Second)))
+	}
+}
+
+// rateLimitResponse is the body of a 429; RetryAfter is in seconds.
+type rateLimitResponse struct {
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"`
+	Global     bool    `json:"global"`
+}
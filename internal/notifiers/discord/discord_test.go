@@ -0,0 +1,158 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+// webhook answers each request with the next reply in replies (204 once they run out) and
+// records when each request arrived and what it carried.
+type webhook struct {
+	replies []func(http.ResponseWriter)
+
+	mu       sync.Mutex
+	arrivals []time.Time
+	messages []message
+}
+
+func (h *webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var msg message
+	_ = json.NewDecoder(r.Body).Decode(&msg)
+	h.mu.Lock()
+	h.arrivals = append(h.arrivals, time.Now())
+	h.messages = append(h.messages, msg)
+	n := len(h.arrivals)
+	h.mu.Unlock()
+	if n <= len(h.replies) {
+		h.replies[n-1](w)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *webhook) snapshot() ([]time.Time, []message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]time.Time(nil), h.arrivals...), append([]message(nil), h.messages...)
+}
+
+func deliver(t *testing.T, n *Notifier, project model.ScrapedProject) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delivery, err := n.SendAlert(ctx, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return delivery.Wait(ctx)
+}
+
+func TestSendAlertRetriesAfterRateLimit(t *testing.T) {
+	h := &webhook{replies: []func(http.ResponseWriter){
+		func(w http.ResponseWriter) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"message":"You are being rate limited.","retry_after":0.2,"global":false}`))
+		},
+	}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	n := New(srv.URL)
+	defer n.Close(context.Background())
+
+	project := model.ScrapedProject{Source: "karlancer", Title: "Go API", Link: "https://www.karlancer.com/project/1"}
+	if err := deliver(t, n, project); err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+
+	arrivals, messages := h.snapshot()
+	if len(arrivals) != 2 {
+		t.Fatalf("got %d requests, want the rate-limited one and a retry", len(arrivals))
+	}
+	if gap := arrivals[1].Sub(arrivals[0]); gap < 200*time.Millisecond {
+		t.Errorf("retried after %v, want at least the 200ms retry_after", gap)
+	}
+	if e := messages[1].Embeds; len(e) != 1 || e[0].Title != "Go API" || e[0].URL != project.Link {
+		t.Errorf("retried embeds = %+v", e)
+	}
+}
+
+func TestSendAlertWaitsForExhaustedBucket(t *testing.T) {
+	bucket := func(remaining, resetAfter string) func(http.ResponseWriter) {
+		return func(w http.ResponseWriter) {
+			w.Header().Set("X-RateLimit-Remaining", remaining)
+			w.Header().Set("X-RateLimit-Reset-After", resetAfter)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+	h := &webhook{replies: []func(http.ResponseWriter){bucket("0", "1"), bucket("4", "1")}}
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	n := New(srv.URL)
+	defer n.Close(context.Background())
+
+	for i := 0; i < 3; i++ {
+		if err := deliver(t, n, model.ScrapedProject{Source: "ponisha", Title: "x"}); err != nil {
+			t.Fatalf("delivery %d failed: %v", i, err)
+		}
+	}
+
+	arrivals, _ := h.snapshot()
+	if len(arrivals) != 3 {
+		t.Fatalf("got %d requests, want 3", len(arrivals))
+	}
+	if gap := arrivals[1].Sub(arrivals[0]); gap < 950*time.Millisecond {
+		t.Errorf("sent %v after the bucket ran out, want to wait for its 1s reset", gap)
+	}
+	if gap := arrivals[2].Sub(arrivals[1]); gap >= 950*time.Millisecond {
+		t.Errorf("waited %v with requests left in the bucket", gap)
+	}
+}
+
+func TestFitEmbedKeepsWithinLimits(t *testing.T) {
+	long := func(n int) string { return strings.Repeat("ب", n) }
+	var fields []field
+	for i := 0; i < 30; i++ {
+		fields = append(fields, field{Name: long(300), Value: long(1500)})
+	}
+	e := fitEmbed(embed{
+		Title:       long(300),
+		Description: long(5000),
+		Fields:      fields,
+		Footer:      &footer{Text: long(3000)},
+	})
+
+	runes := func(s string) int { return len([]rune(s)) }
+	if runes(e.Title) > maxTitle || runes(e.Description) > maxDescription || runes(e.Footer.Text) > maxFooter {
+		t.Errorf("title %d, description %d, footer %d characters", runes(e.Title), runes(e.Description), runes(e.Footer.Text))
+	}
+	if len(e.Fields) > maxFields {
+		t.Errorf("%d fields, want at most %d", len(e.Fields), maxFields)
+	}
+	for i, f := range e.Fields {
+		if runes(f.Name) > maxFieldName || runes(f.Value) > maxFieldValue {
+			t.Errorf("field %d: name %d, value %d characters", i, runes(f.Name), runes(f.Value))
+		}
+	}
+	if total := embedLength(e); total > maxEmbedTotal {
+		t.Errorf("embed has %d characters, want at most %d", total, maxEmbedTotal)
+	}
+	if !strings.HasSuffix(e.Title, "…") {
+		t.Errorf("truncated title %q does not end with an ellipsis", e.Title)
+	}
+
+	small := embed{Title: "Go API", Description: "short", Fields: []field{{Name: "Budget", Value: "20M"}}}
+	if got := fitEmbed(small); got.Title != small.Title || got.Description != small.Description || len(got.Fields) != 1 {
+		t.Errorf("fitEmbed changed an embed within limits: %+v", got)
+	}
+}
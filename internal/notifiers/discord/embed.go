@@ -0,0 +1,172 @@
+package discord
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+type message struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []embed `json:"embeds"`
+}
+
+type embed struct {
+	Title       string  `json:"title,omitempty"`
+	URL         string  `json:"url,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Color       int     `json:"color,omitempty"`
+	Fields      []field `json:"fields,omitempty"`
+	Footer      *footer `json:"footer,omitempty"`
+	Timestamp   string  `json:"timestamp,omitempty"`
+}
+
+type field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type footer struct {
+	Text string `json:"text"`
+}
+
+// Embed limits, in characters; Discord rejects the whole message when one is exceeded.
+const (
+	maxTitle       = 256
+	maxDescription = 4096
+	maxFieldName   = 256
+	maxFieldValue  = 1024
+	maxFields      = 25
+	maxFooter      = 2048
+	maxEmbedTotal  = 6000
+)
+
+// sourceColors keeps each provider's alerts recognisable; other sources get a colour
+// derived from their name.
+var sourceColors = map[string]int{
+	"ponisha":   0x2F80ED,
+	"karlancer": 0xF2994A,
+}
+
+func sourceColor(source string) int {
+	if color, ok := sourceColors[strings.ToLower(source)]; ok {
+		return color
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.ToLower(source)))
+	return int(h.Sum32() & 0xFFFFFF)
+}
+
+func projectMessage(project model.ScrapedProject) message {
+	return message{Embeds: []embed{projectEmbed(project)}}
+}
+
+func projectEmbed(project model.ScrapedProject) embed {
+	skills := "—"
+	if len(project.Skills) > 0 {
+		skills = strings.Join(project.Skills, ", ")
+	}
+	budget := project.BudgetText
+	if budget == "" {
+		budget = "—"
+	}
+
+	e := embed{
+		Title:       project.Title,
+		URL:         project.Link,
+		Description: project.Description,
+		Color:       sourceColor(project.Source),
+		Fields: []field{
+			{Name: "Budget", Value: budget, Inline: true},
+			{Name: "Source", Value: project.Source, Inline: true},
+		},
+		Footer: &footer{Text: project.Source},
+	}
+	if project.BidsCount != nil {
+		e.Fields = append(e.Fields, field{Name: "Bids", Value: fmt.Sprintf("%d", *project.BidsCount), Inline: true})
+	}
+	e.Fields = append(e.Fields, field{Name: "Skills", Value: skills})
+	if deadline, err := notifiers.ParseTime(project.BiddingClosedAt); err == nil {
+		// Discord renders <t:…> in each reader's own timezone.
+		e.Fields = append(e.Fields, field{Name: "Deadline", Value: fmt.Sprintf("<t:%d:f> (<t:%d:R>)", deadline.Unix(), deadline.Unix())})
+	}
+	if len(project.Annotations) > 0 {
+		e.Fields = append(e.Fields, field{Name: "Tags", Value: notifiers.FormatAnnotations(project.Annotations)})
+	}
+	if approved, err := notifiers.ParseTime(project.ApprovedAt); err == nil {
+		e.Timestamp = approved.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return fitEmbed(e)
+}
+
+func systemAlertMessage(alert model.SystemAlert) message {
+	title := "System alert"
+	color := 0xF2C94C
+	switch alert.Kind {
+	case model.AlertLayoutChanged:
+		title = "Page layout changed"
+	case model.AlertProviderDown:
+		title = "Provider down"
+		color = 0xEB5757
+	case model.AlertProviderRecovered:
+		title = "Provider recovered"
+		color = 0x27AE60
+	}
+	return message{Embeds: []embed{fitEmbed(embed{
+		Title:       "⚠️ " + title,
+		Description: alert.Message,
+		Color:       color,
+		Footer:      &footer{Text: alert.Source},
+	})}}
+}
+
+// fitEmbed truncates an embed to Discord's per-field limits, then trims the description
+// (and, if that is not enough, drops trailing fields) until the embed fits the total limit.
+func fitEmbed(e embed) embed {
+	e.Title = truncate(e.Title, maxTitle)
+	e.Description = truncate(e.Description, maxDescription)
+	if len(e.Fields) > maxFields {
+		e.Fields = e.Fields[:maxFields]
+	}
+	for i := range e.Fields {
+		e.Fields[i].Name = truncate(e.Fields[i].Name, maxFieldName)
+		e.Fields[i].Value = truncate(e.Fields[i].Value, maxFieldValue)
+	}
+	if e.Footer != nil {
+		e.Footer.Text = truncate(e.Footer.Text, maxFooter)
+	}
+
+	if over := embedLength(e) - maxEmbedTotal; over > 0 {
+		e.Description = truncate(e.Description, max(len([]rune(e.Description))-over, 0))
+	}
+	for embedLength(e) > maxEmbedTotal && len(e.Fields) > 0 {
+		e.Fields = e.Fields[:len(e.Fields)-1]
+	}
+	return e
+}
+
+func embedLength(e embed) int {
+	n := len([]rune(e.Title)) + len([]rune(e.Description))
+	for _, f := range e.Fields {
+		n += len([]rune(f.Name)) + len([]rune(f.Value))
+	}
+	if e.Footer != nil {
+		n += len([]rune(e.Footer.Text))
+	}
+	return n
+}
+
+func truncate(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	if limit == 0 {
+		return ""
+	}
+	return string(runes[:limit-1]) + "…"
+}
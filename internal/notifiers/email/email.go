@@ -0,0 +1,382 @@
+// Package email delivers alerts over SMTP as multipart HTML and plain-text messages, one per
+// recipient, honouring each recipient's filter. Recipients with a digest schedule get one
+// summary per window instead.
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+	"ponisha-go/internal/tracing"
+)
+
+// Server is where and how to submit mail.
+type Server struct {
+	Host string
+	Port string
+	// Username enables AUTH PLAIN; net/smtp only sends it over TLS or to localhost.
+	Username string
+	Password string
+	// StartTLS requires the server to offer STARTTLS and upgrades before authenticating.
+	StartTLS bool
+}
+
+// Notifier sends each alert to the recipients whose filter it passes.
+type Notifier struct {
+	server     Server
+	from       string
+	fromAddr   string
+	recipients []Recipient
+
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	queue     *notifiers.Queue[outgoing]
+	queueSize int
+
+	digestOrder notifiers.DigestOrder
+	digestLimit int
+	digests     map[notifiers.DigestSchedule]*notifiers.Digest
+
+	metrics Metrics
+}
+
+// Metrics receives the latency and outcome of every delivered message.
+type Metrics interface {
+	ObserveSend(notifier string, duration time.Duration, err error)
+}
+
+type Option func(*Notifier)
+
+func WithMetrics(metrics Metrics) Option {
+	return func(n *Notifier) {
+		n.metrics = metrics
+	}
+}
+
+// WithTLSConfig replaces the STARTTLS configuration, e.g. to trust a test server's certificate.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(n *Notifier) {
+		n.tlsConfig = config
+	}
+}
+
+// WithTimeout bounds one SMTP session (30s by default).
+func WithTimeout(timeout time.Duration) Option {
+	return func(n *Notifier) {
+		n.timeout = timeout
+	}
+}
+
+// WithDigestOrder sets how digests list projects (by budget by default).
+func WithDigestOrder(order notifiers.DigestOrder) Option {
+	return func(n *Notifier) {
+		n.digestOrder = order
+	}
+}
+
+// WithDigestLimit caps how many projects each digest holds (notifiers.DefaultDigestLimit by
+// default).
+func WithDigestLimit(limit int) Option {
+	return func(n *Notifier) {
+		n.digestLimit = limit
+	}
+}
+
+// WithQueueSize sets how many alerts may wait to be sent (100 by default).
+func WithQueueSize(size int) Option {
+	return func(n *Notifier) {
+		n.queueSize = size
+	}
+}
+
+// outgoing is one queued send: an email per address. Alerts give every address the same
+// email; digests are rendered per recipient.
+type outgoing struct {
+	letters []letter
+}
+
+type letter struct {
+	to   string
+	mail rendered
+}
+
+func sameMail(content rendered, to []string) outgoing {
+	item := outgoing{letters: make([]letter, 0, len(to))}
+	for _, addr := range to {
+		item.letters = append(item.letters, letter{to: addr, mail: content})
+	}
+	return item
+}
+
+// New returns a Notifier sending from the given address, which may include a display name.
+func New(server Server, from string, recipients []Recipient, options ...Option) (*Notifier, error) {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender address %q: %w", from, err)
+	}
+	for _, recipient := range recipients {
+		if _, err := mail.ParseAddress(recipient.Address); err != nil {
+			return nil, fmt.Errorf("invalid recipient address %q: %w", recipient.Address, err)
+		}
+	}
+
+	n := &Notifier{
+		server:      server,
+		from:        addr.String(),
+		fromAddr:    addr.Address,
+		recipients:  recipients,
+		tlsConfig:   &tls.Config{ServerName: server.Host},
+		timeout:     30 * time.Second,
+		queueSize:   100,
+		digestLimit: notifiers.DefaultDigestLimit,
+	}
+	for _, option := range options {
+		option(n)
+	}
+
+	n.queue = notifiers.NewQueue(n.queueSize, n.send)
+	n.digests = map[notifiers.DigestSchedule]*notifiers.Digest{}
+	for _, recipient := range recipients {
+		schedule := recipient.Digest
+		if schedule.Enabled() && n.digests[schedule] == nil {
+			n.digests[schedule] = notifiers.NewDigest(schedule, n.digestOrder, func(ctx context.Context, digest model.Digest) error {
+				return n.sendDigest(ctx, schedule, digest)
+			}, notifiers.WithDigestLimit(n.digestLimit))
+		}
+	}
+	return n, nil
+}
+
+// SendAlert queues the alert for the recipients whose filter matches, waiting for room in
+// the queue until ctx is done, and adds it to the digests of matching digest recipients. The
+// returned Delivery completes once the mail and every digest listing the project are sent.
+func (n *Notifier) SendAlert(ctx context.Context, project model.ScrapedProject) (*notifiers.Delivery, error) {
+	var to []string
+	wanted := map[*notifiers.Digest]bool{}
+	for _, recipient := range n.recipients {
+		if !recipient.Filter.Match(project) {
+			continue
+		}
+		if digest := n.digests[recipient.Digest]; digest != nil {
+			wanted[digest] = true
+			continue
+		}
+		to = append(to, recipient.Address)
+	}
+	var deliveries []*notifiers.Delivery
+	if len(to) > 0 {
+		content, err := renderProject(project)
+		if err != nil {
+			return nil, fmt.Errorf("email: %w", err)
+		}
+		delivery, err := n.enqueue(ctx, sameMail(content, to))
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	for digest := range wanted {
+		deliveries = append(deliveries, digest.Add(project))
+	}
+	if len(deliveries) == 0 {
+		slog.DebugContext(ctx, "email alert has no recipient", "external_id", project.ExternalID)
+	}
+	return notifiers.All(deliveries...), nil
+}
+
+// sendDigest mails each recipient on schedule the digest's projects that pass their filter,
+// and waits until the mails have been sent.
+func (n *Notifier) sendDigest(ctx context.Context, schedule notifiers.DigestSchedule, digest model.Digest) error {
+	var item outgoing
+	for _, recipient := range n.recipients {
+		if recipient.Digest != schedule {
+			continue
+		}
+		own := digest
+		own.Projects = nil
+		for _, project := range digest.Projects {
+			if recipient.Filter.Match(project) {
+				own.Projects = append(own.Projects, project)
+			}
+		}
+		if len(own.Projects) == 0 {
+			continue
+		}
+		content, err := renderDigest(own)
+		if err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+		item.letters = append(item.letters, letter{to: recipient.Address, mail: content})
+	}
+	if len(item.letters) == 0 {
+		return nil
+	}
+	delivery, err := n.enqueue(ctx, item)
+	if err != nil {
+		return err
+	}
+	return delivery.Wait(ctx)
+}
+
+func (n *Notifier) RenderAlert(project model.ScrapedProject) string {
+	content, err := renderProject(project)
+	if err != nil {
+		return err.Error()
+	}
+	return content.Text
+}
+
+// SendSystemAlert goes to every recipient regardless of filters.
+func (n *Notifier) SendSystemAlert(ctx context.Context, alert model.SystemAlert) (*notifiers.Delivery, error) {
+	content, err := renderSystemAlert(alert)
+	if err != nil {
+		return nil, fmt.Errorf("email: %w", err)
+	}
+	to := make([]string, 0, len(n.recipients))
+	for _, recipient := range n.recipients {
+		to = append(to, recipient.Address)
+	}
+	return n.enqueue(ctx, sameMail(content, to))
+}
+
+// QueueDepth is the number of alerts waiting to be sent.
+func (n *Notifier) QueueDepth() int {
+	return n.queue.Len()
+}
+
+// Close stops accepting alerts and waits until the queued ones have been sent, after sending
+// the digests collected so far.
+func (n *Notifier) Close(ctx context.Context) error {
+	var errs []error
+	for _, digest := range n.digests {
+		errs = append(errs, digest.Close(ctx))
+	}
+	return errors.Join(append(errs, n.queue.Close(ctx))...)
+}
+
+func (n *Notifier) enqueue(ctx context.Context, item outgoing) (*notifiers.Delivery, error) {
+	delivery, err := n.queue.Enqueue(ctx, item)
+	if err != nil {
+		slog.WarnContext(ctx, "email not queued", "error", err)
+		return nil, fmt.Errorf("email: %w", err)
+	}
+	return delivery, nil
+}
+
+// send delivers one message per recipient over a single SMTP session, so recipients never
+// see each other's addresses. It fails if any recipient could not be sent to.
+func (n *Notifier) send(ctx context.Context, item outgoing) error {
+	start := time.Now()
+	err := n.deliver(ctx, item)
+	if n.metrics != nil {
+		n.metrics.ObserveSend("email", time.Since(start), err)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "email send error", "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "email alert sent", "recipients", len(item.letters))
+	return nil
+}
+
+func (n *Notifier) deliver(ctx context.Context, item outgoing) (err error) {
+	ctx, span := tracing.Start(ctx, "email.send")
+	defer func() { tracing.End(span, err) }()
+
+	client, err := n.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	var errs []error
+	for _, l := range item.letters {
+		if err := n.sendOne(client, l.to, l.mail); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", l.to, err))
+			if resetErr := client.Reset(); resetErr != nil {
+				return errors.Join(append(errs, resetErr)...)
+			}
+		}
+	}
+	if err := client.Quit(); err != nil && len(errs) == 0 {
+		slog.DebugContext(ctx, "smtp quit failed", "error", err)
+	}
+	return errors.Join(errs...)
+}
+
+// dial connects, upgrades with STARTTLS when configured and authenticates.
+func (n *Notifier) dial(ctx context.Context) (*smtp.Client, error) {
+	dialer := net.Dialer{Timeout: n.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(n.server.Host, n.server.Port))
+	if err != nil {
+		return nil, fmt.Errorf("smtp connect: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(n.timeout))
+
+	client, err := smtp.NewClient(conn, n.server.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp greeting: %w", err)
+	}
+
+	if n.server.StartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp server does not offer STARTTLS")
+		}
+		if err := client.StartTLS(n.tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	if n.server.Username != "" {
+		auth := smtp.PlainAuth("", n.server.Username, n.server.Password, n.server.Host)
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	return client, nil
+}
+
+func (n *Notifier) sendOne(client *smtp.Client, to string, content rendered) error {
+	msg, err := buildMessage(n.from, to, content, time.Now())
+	if err != nil {
+		return err
+	}
+	addr, err := parseAddress(to)
+	if err != nil {
+		return err
+	}
+	if err := client.Mail(n.fromAddr); err != nil {
+		return err
+	}
+	if err := client.Rcpt(addr); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func parseAddress(value string) (string, error) {
+	addr, err := mail.ParseAddress(value)
+	if err != nil {
+		return "", err
+	}
+	return addr.Address, nil
+}
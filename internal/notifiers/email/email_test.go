@@ -0,0 +1,229 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+// smtpServer is a minimal SMTP server that accepts everything except the addresses in
+// reject, and records one envelope per DATA command.
+type smtpServer struct {
+	listener net.Listener
+	reject   map[string]bool
+
+	mu        sync.Mutex
+	sessions  int
+	envelopes []envelope
+}
+
+type envelope struct {
+	from string
+	to   []string
+	data string
+}
+
+func newSMTPServer(t *testing.T, reject ...string) *smtpServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{listener: listener, reject: map[string]bool{}}
+	for _, addr := range reject {
+		s.reject[addr] = true
+	}
+	go s.serve()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *smtpServer) server() Server {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return Server{Host: host, Port: port}
+}
+
+func (s *smtpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.session(conn)
+	}
+}
+
+func (s *smtpServer) session(conn net.Conn) {
+	defer conn.Close()
+	s.mu.Lock()
+	s.sessions++
+	s.mu.Unlock()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP")
+	var current envelope
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case "MAIL":
+			current = envelope{from: addressOf(line)}
+			reply("250 OK")
+		case "RCPT":
+			addr := addressOf(line)
+			if s.reject[addr] {
+				reply("550 no such user")
+				continue
+			}
+			current.to = append(current.to, addr)
+			reply("250 OK")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			current.data = data.String()
+			s.mu.Lock()
+			s.envelopes = append(s.envelopes, current)
+			s.mu.Unlock()
+			reply("250 queued")
+		case "RSET":
+			current = envelope{}
+			reply("250 OK")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func addressOf(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func (s *smtpServer) snapshot() (int, []envelope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sessions, append([]envelope(nil), s.envelopes...)
+}
+
+func deliver(t *testing.T, n *Notifier, project model.ScrapedProject) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delivery, err := n.SendAlert(ctx, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return delivery.Wait(ctx)
+}
+
+func TestSendAlertMailsMatchingRecipientsInOneSession(t *testing.T) {
+	srv := newSMTPServer(t)
+	recipients := []Recipient{
+		{Address: "alice@example.com"},
+		{Address: "Bob <bob@example.com>", Filter: Filter{Sources: []string{"karlancer"}}},
+		{Address: "carol@example.com", Filter: Filter{MinBudget: 100_000_000}},
+	}
+	n, err := New(srv.server(), "Alerts <alerts@example.com>", recipients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close(context.Background())
+
+	project := model.ScrapedProject{Source: "karlancer", Title: "Go API", Link: "https://www.karlancer.com/project/1", AmountMax: 20_000_000}
+	if err := deliver(t, n, project); err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+
+	sessions, envelopes := srv.snapshot()
+	if sessions != 1 {
+		t.Errorf("got %d SMTP sessions, want 1", sessions)
+	}
+	if len(envelopes) != 2 {
+		t.Fatalf("got %d messages, want one each for alice and bob", len(envelopes))
+	}
+	for i, want := range []string{"alice@example.com", "bob@example.com"} {
+		e := envelopes[i]
+		if e.from != "alerts@example.com" {
+			t.Errorf("message %d: MAIL FROM %q", i, e.from)
+		}
+		if len(e.to) != 1 || e.to[0] != want {
+			t.Errorf("message %d: RCPT TO %v, want only %s", i, e.to, want)
+		}
+		if !strings.Contains(e.data, want) || strings.Contains(e.data, "carol@") {
+			t.Errorf("message %d headers do not address only %s:\n%s", i, want, e.data)
+		}
+		if !strings.Contains(e.data, "multipart/alternative") {
+			t.Errorf("message %d is not multipart", i)
+		}
+	}
+}
+
+func TestSendAlertReportsRejectedRecipientAndMailsTheRest(t *testing.T) {
+	srv := newSMTPServer(t, "gone@example.com")
+	n, err := New(srv.server(), "alerts@example.com", []Recipient{
+		{Address: "gone@example.com"},
+		{Address: "alice@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close(context.Background())
+
+	err = deliver(t, n, model.ScrapedProject{Source: "ponisha", Title: "x"})
+	if err == nil || !strings.Contains(err.Error(), "gone@example.com") {
+		t.Fatalf("delivery error = %v, want the rejected recipient", err)
+	}
+	_, envelopes := srv.snapshot()
+	if len(envelopes) != 1 || envelopes[0].to[0] != "alice@example.com" {
+		t.Errorf("messages = %+v, want alice's only", envelopes)
+	}
+}
+
+func TestSendAlertFailsWithoutOfferedStartTLS(t *testing.T) {
+	srv := newSMTPServer(t)
+	server := srv.server()
+	server.StartTLS = true
+	n, err := New(server, "alerts@example.com", []Recipient{{Address: "alice@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer n.Close(context.Background())
+
+	err = deliver(t, n, model.ScrapedProject{Source: "ponisha", Title: "x"})
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("delivery error = %v, want missing STARTTLS", err)
+	}
+	if _, envelopes := srv.snapshot(); len(envelopes) != 0 {
+		t.Errorf("sent %d messages in plain text", len(envelopes))
+	}
+}
@@ -0,0 +1,75 @@
+package email
+
+import (
+	"strings"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+// Recipient is one address and the projects it wants to hear about.
+type Recipient struct {
+	Address string
+	Filter  Filter
+	// Digest batches the recipient's alerts into one email per window; the zero schedule
+	// sends each alert at once.
+	Digest notifiers.DigestSchedule
+}
+
+// Filter narrows the projects sent to a recipient. Empty fields match everything; a project
+// must pass every non-empty field.
+type Filter struct {
+	// Sources are provider names, e.g. "ponisha".
+	Sources []string
+	// Skills match when the project lists at least one of them.
+	Skills []string
+	// Keywords match when one appears in the title or description.
+	Keywords []string
+	// MinBudget is in toman and compared with the top of the project's budget range.
+	MinBudget int64
+}
+
+// Match reports whether project passes the filter. Comparisons ignore case.
+func (f Filter) Match(project model.ScrapedProject) bool {
+	if len(f.Sources) > 0 && !containsFold(f.Sources, project.Source) {
+		return false
+	}
+	if len(f.Skills) > 0 && !anySkill(f.Skills, project.Skills) {
+		return false
+	}
+	if len(f.Keywords) > 0 {
+		text := strings.ToLower(project.Title + "\n" + project.Description)
+		found := false
+		for _, keyword := range f.Keywords {
+			if strings.Contains(text, strings.ToLower(keyword)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.MinBudget > 0 && max(project.AmountMin, project.AmountMax) < f.MinBudget {
+		return false
+	}
+	return true
+}
+
+func anySkill(wanted, skills []string) bool {
+	for _, skill := range skills {
+		if containsFold(wanted, strings.TrimSpace(skill)) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/yaa110/go-persian-calendar"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html.tmpl"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt.tmpl"))
+)
+
+// rlm (RIGHT-TO-LEFT MARK) starts every plain-text line so mail clients lay out lines that
+// begin with a Latin title, skill or URL right to left like the Persian text around them.
+const rlm = "‏"
+
+// rendered is one email: the subject and both alternative bodies.
+type rendered struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type projectView struct {
+	Title       string
+	Link        string
+	Source      string
+	Budget      string
+	Skills      string
+	Description string
+	ApprovedAt  string
+	Deadline    string
+	Bids        string
+	Annotations string
+}
+
+type digestView struct {
+	Title    string
+	From     string
+	To       string
+	Projects []digestItem
+}
+
+type digestItem struct {
+	Index    int
+	Title    string
+	Link     string
+	Source   string
+	Budget   string
+	Deadline string
+	Bids     string
+}
+
+type systemView struct {
+	Title   string
+	Source  string
+	Message string
+}
+
+func renderProject(project model.ScrapedProject) (rendered, error) {
+	view := projectView{
+		Title:       project.Title,
+		Link:        project.Link,
+		Source:      project.Source,
+		Budget:      orDash(project.BudgetText),
+		Skills:      orDash(strings.Join(project.Skills, "، ")),
+		Description: project.Description,
+		ApprovedAt:  formatPersianTime(project.ApprovedAt),
+		Deadline:    formatPersianTime(project.BiddingClosedAt),
+		Annotations: notifiers.FormatAnnotations(project.Annotations),
+	}
+	if project.BidsCount != nil {
+		view.Bids = strconv.Itoa(*project.BidsCount)
+	}
+	return render("alert", fmt.Sprintf("پروژه جدید: %s", project.Title), view)
+}
+
+func renderDigest(digest model.Digest) (rendered, error) {
+	title := fmt.Sprintf("خلاصه پروژه‌ها: %d پروژه", len(digest.Projects))
+	view := digestView{
+		Title: title,
+		From:  ptime.New(digest.From.In(notifiers.Tehran)).Format("yyyy/MM/dd HH:mm"),
+		To:    ptime.New(digest.To.In(notifiers.Tehran)).Format("yyyy/MM/dd HH:mm"),
+	}
+	for i, project := range digest.Projects {
+		item := digestItem{
+			Index:    i + 1,
+			Title:    project.Title,
+			Link:     project.Link,
+			Source:   project.Source,
+			Budget:   orDash(project.BudgetText),
+			Deadline: formatPersianTime(project.BiddingClosedAt),
+		}
+		if project.BidsCount != nil {
+			item.Bids = strconv.Itoa(*project.BidsCount)
+		}
+		view.Projects = append(view.Projects, item)
+	}
+	return render("digest", title, view)
+}
+
+func renderSystemAlert(alert model.SystemAlert) (rendered, error) {
+	title := "هشدار سیستم"
+	switch alert.Kind {
+	case model.AlertLayoutChanged:
+		title = "تغییر ساختار صفحه"
+	case model.AlertProviderDown:
+		title = "منبع از دسترس خارج شد"
+	case model.AlertProviderRecovered:
+		title = "منبع دوباره در دسترس است"
+	}
+	view := systemView{Title: title, Source: alert.Source, Message: alert.Message}
+	return render("system", fmt.Sprintf("%s: %s", title, alert.Source), view)
+}
+
+func render(name, subject string, view any) (rendered, error) {
+	var html, text bytes.Buffer
+	if err := htmlTemplates.ExecuteTemplate(&html, name+".html.tmpl", view); err != nil {
+		return rendered{}, fmt.Errorf("render %s html: %w", name, err)
+	}
+	if err := textTemplates.ExecuteTemplate(&text, name+".txt.tmpl", view); err != nil {
+		return rendered{}, fmt.Errorf("render %s text: %w", name, err)
+	}
+	return rendered{Subject: subject, Text: rightToLeft(text.String()), HTML: html.String()}, nil
+}
+
+func rightToLeft(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = rlm + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatPersianTime(value string) string {
+	if value == "" {
+		return ""
+	}
+	parsed, err := notifiers.ParseTime(value)
+	if err != nil {
+		return ""
+	}
+	return ptime.New(parsed).Format("yyyy/MM/dd HH:mm")
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "—"
+	}
+	return value
+}
@@ -0,0 +1,43 @@
+package notifiers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ParseTime reads the timestamp formats providers use for approval and deadline dates.
+func ParseTime(value string) (time.Time, error) {
+	layouts := []string{
+		time.RFC3339,
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05",
+	}
+	for _, layout := range layouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unsupported time format: %s", value)
+}
+
+// FormatAnnotations renders pipeline annotations as "key=value" pairs sorted by key, or just
+// the key when the value is empty.
+func FormatAnnotations(annotations map[string]string) string {
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if value := annotations[key]; value != "" {
+			parts = append(parts, key+"="+value)
+		} else {
+			parts = append(parts, key)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,109 @@
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Queue sends messages one at a time from a bounded buffer on its own goroutine.
+type Queue[T any] struct {
+	items chan queued[T]
+	send  func(ctx context.Context, item T) error
+
+	// mu keeps Close from closing items during a send. closing is closed first, so that
+	// Enqueue calls waiting for room give up and release mu.
+	mu          sync.RWMutex
+	closed      bool
+	closing     chan struct{}
+	closingOnce sync.Once
+	done        chan struct{}
+}
+
+type queued[T any] struct {
+	ctx      context.Context
+	item     T
+	delivery *Delivery
+}
+
+// NewQueue starts a queue holding up to size messages that are passed to send in order.
+func NewQueue[T any](size int, send func(ctx context.Context, item T) error) *Queue[T] {
+	q := &Queue[T]{
+		items:   make(chan queued[T], size),
+		send:    send,
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go q.worker()
+	return q
+}
+
+// Enqueue adds item, waiting for room until ctx is done; after that it gives up with
+// ErrQueueFull. send later receives ctx's values but not its cancellation, since delivery
+// outlives the call that queued the message.
+func (q *Queue[T]) Enqueue(ctx context.Context, item T) (*Delivery, error) {
+	return q.enqueue(ctx, item, true)
+}
+
+// TryEnqueue is Enqueue without the wait: it fails with ErrQueueFull when there is no room.
+func (q *Queue[T]) TryEnqueue(ctx context.Context, item T) (*Delivery, error) {
+	return q.enqueue(ctx, item, false)
+}
+
+func (q *Queue[T]) enqueue(ctx context.Context, item T, wait bool) (*Delivery, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return nil, ErrClosed
+	}
+
+	entry := queued[T]{ctx: context.WithoutCancel(ctx), item: item, delivery: NewDelivery()}
+	// Take free room even when ctx has already expired.
+	select {
+	case q.items <- entry:
+		return entry.delivery, nil
+	default:
+	}
+	if !wait {
+		return nil, ErrQueueFull
+	}
+	select {
+	case q.items <- entry:
+		return entry.delivery, nil
+	case <-q.closing:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrQueueFull, ctx.Err())
+	}
+}
+
+// Len is the number of messages waiting to be sent.
+func (q *Queue[T]) Len() int {
+	return len(q.items)
+}
+
+// Close stops accepting messages and waits until the queued ones have been sent. Enqueue
+// calls still waiting for room fail with ErrClosed.
+func (q *Queue[T]) Close(ctx context.Context) error {
+	q.closingOnce.Do(func() { close(q.closing) })
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.items)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (q *Queue[T]) worker() {
+	defer close(q.done)
+	for entry := range q.items {
+		entry.delivery.Complete(q.send(entry.ctx, entry.item))
+	}
+}
@@ -0,0 +1,60 @@
+package notifiers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQueueCloseReleasesWaitingEnqueue(t *testing.T) {
+	release := make(chan struct{})
+	q := NewQueue(1, func(context.Context, int) error {
+		<-release
+		return nil
+	})
+	defer close(release)
+
+	// The worker holds the first message and the second fills the buffer.
+	for i := 0; i < 2; i++ {
+		if _, err := q.Enqueue(context.Background(), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for q.Len() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := q.Enqueue(context.Background(), 2)
+		waiting <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	closed := make(chan error, 1)
+	go func() { closed <- q.Close(ctx) }()
+
+	select {
+	case err := <-closed:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Close = %v, want its context's deadline while the worker is busy", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close blocked past its context behind a waiting Enqueue")
+	}
+	select {
+	case err := <-waiting:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("waiting Enqueue = %v, want ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Enqueue still waiting after Close")
+	}
+	if _, err := q.TryEnqueue(context.Background(), 3); !errors.Is(err, ErrClosed) {
+		t.Errorf("TryEnqueue after Close = %v, want ErrClosed", err)
+	}
+}
@@ -0,0 +1,86 @@
+package notifiers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tehran is the zone digest schedules and displayed times use.
+var Tehran = loadTehran()
+
+func loadTehran() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Tehran"); err == nil {
+		return loc
+	}
+	return time.FixedZone("IRST", 3*60*60+30*60)
+}
+
+const (
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+)
+
+// DigestSchedule says when a digest goes out: at the top of every hour, or once a day at
+// Hour:Minute, both in Tehran time. The zero value means no digest; alerts are sent one by one.
+type DigestSchedule struct {
+	// Period is DigestHourly, DigestDaily or empty.
+	Period string
+	Hour   int
+	Minute int
+}
+
+// ParseDigestSchedule reads "hourly", "daily" (09:00), "daily@HH:MM", or "off" and "" for
+// no digest.
+func ParseDigestSchedule(value string) (DigestSchedule, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	period, at, hasTime := strings.Cut(value, "@")
+	switch {
+	case value == "" || value == "off":
+		return DigestSchedule{}, nil
+	case value == DigestHourly:
+		return DigestSchedule{Period: DigestHourly}, nil
+	case period == DigestDaily && !hasTime:
+		return DigestSchedule{Period: DigestDaily, Hour: 9}, nil
+	case period == DigestDaily:
+		clock, err := time.Parse("15:04", at)
+		if err != nil {
+			return DigestSchedule{}, fmt.Errorf("invalid digest time %q: want HH:MM", at)
+		}
+		return DigestSchedule{Period: DigestDaily, Hour: clock.Hour(), Minute: clock.Minute()}, nil
+	default:
+		return DigestSchedule{}, fmt.Errorf("invalid digest schedule %q: want hourly, daily@HH:MM or off", value)
+	}
+}
+
+func (s DigestSchedule) Enabled() bool {
+	return s.Period != ""
+}
+
+func (s DigestSchedule) String() string {
+	switch s.Period {
+	case DigestHourly:
+		return DigestHourly
+	case DigestDaily:
+		return fmt.Sprintf("%s@%02d:%02d", DigestDaily, s.Hour, s.Minute)
+	default:
+		return "off"
+	}
+}
+
+// Next is the first send time after t, or the zero time when the schedule is off.
+func (s DigestSchedule) Next(t time.Time) time.Time {
+	local := t.In(Tehran)
+	switch s.Period {
+	case DigestHourly:
+		return time.Date(local.Year(), local.Month(), local.Day(), local.Hour()+1, 0, 0, 0, Tehran)
+	case DigestDaily:
+		next := time.Date(local.Year(), local.Month(), local.Day(), s.Hour, s.Minute, 0, 0, Tehran)
+		if !next.After(local) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	default:
+		return time.Time{}
+	}
+}
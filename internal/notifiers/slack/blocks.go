@@ -0,0 +1,118 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+// message is an incoming webhook payload. Text is the notification and fallback text;
+// Blocks is what the channel shows.
+type message struct {
+	Text   string  `json:"text"`
+	Blocks []block `json:"blocks,omitempty"`
+}
+
+type block struct {
+	Type     string `json:"type"`
+	Text     *text  `json:"text,omitempty"`
+	Fields   []text `json:"fields,omitempty"`
+	Elements []text `json:"elements,omitempty"`
+}
+
+type text struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Slack rejects section text over 3000 characters and field text over 2000.
+const (
+	maxSectionText = 3000
+	maxFieldText   = 2000
+)
+
+func projectMessage(project model.ScrapedProject) message {
+	title := fmt.Sprintf("*<%s|%s>*", escape(project.Link), escape(project.Title))
+	if project.Link == "" {
+		title = fmt.Sprintf("*%s*", escape(project.Title))
+	}
+
+	fields := []text{
+		mrkdwn("*Budget*\n"+escape(orDash(project.BudgetText)), maxFieldText),
+		mrkdwn("*Source*\n"+escape(project.Source), maxFieldText),
+		mrkdwn("*Skills*\n"+escape(orDash(strings.Join(project.Skills, ", "))), maxFieldText),
+		mrkdwn("*Deadline*\n"+orDash(formatTime(project.BiddingClosedAt)), maxFieldText),
+	}
+	if project.BidsCount != nil {
+		fields = append(fields, mrkdwn(fmt.Sprintf("*Bids*\n%d", *project.BidsCount), maxFieldText))
+	}
+
+	blocks := []block{
+		{Type: "section", Text: ptr(mrkdwn(title, maxSectionText))},
+		{Type: "section", Fields: fields},
+	}
+	if project.Description != "" {
+		blocks = append(blocks, block{Type: "section", Text: ptr(mrkdwn(escape(project.Description), maxSectionText))})
+	}
+	if len(project.Annotations) > 0 {
+		blocks = append(blocks, block{Type: "context", Elements: []text{mrkdwn(escape(notifiers.FormatAnnotations(project.Annotations)), maxFieldText)}})
+	}
+
+	return message{
+		Text:   fmt.Sprintf("%s (%s): %s", project.Title, project.Source, orDash(project.BudgetText)),
+		Blocks: blocks,
+	}
+}
+
+func systemAlertMessage(alert model.SystemAlert) message {
+	title := "System alert"
+	switch alert.Kind {
+	case model.AlertLayoutChanged:
+		title = "Page layout changed"
+	case model.AlertProviderDown:
+		title = ":red_circle: Provider down"
+	case model.AlertProviderRecovered:
+		title = ":large_green_circle: Provider recovered"
+	}
+	body := fmt.Sprintf(":warning: *%s* (%s)\n%s", title, escape(alert.Source), escape(alert.Message))
+	return message{
+		Text:   fmt.Sprintf("%s: %s", alert.Source, alert.Message),
+		Blocks: []block{{Type: "section", Text: ptr(mrkdwn(body, maxSectionText))}},
+	}
+}
+
+func mrkdwn(value string, limit int) text {
+	if runes := []rune(value); len(runes) > limit {
+		value = string(runes[:limit-1]) + "…"
+	}
+	return text{Type: "mrkdwn", Text: value}
+}
+
+func ptr(t text) *text {
+	return &t
+}
+
+// escape replaces the characters Slack treats as control sequences in mrkdwn.
+func escape(value string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(value)
+}
+
+func formatTime(value string) string {
+	if value == "" {
+		return ""
+	}
+	parsed, err := notifiers.ParseTime(value)
+	if err != nil {
+		return ""
+	}
+	return parsed.In(notifiers.Tehran).Format("2006-01-02 15:04") + " (Tehran)"
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "—"
+	}
+	return value
+}
@@ -0,0 +1,94 @@
+package slack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+const (
+	// Sections per message, keeping it well under Slack's 50 blocks and 40,000 characters.
+	maxDigestSections = 10
+	// Titles and links are capped so a single line always fits in one section.
+	maxDigestTitle = 120
+	maxDigestLink  = 2000
+)
+
+// digestMessages renders the digest as one line per project, packed into sections under
+// Slack's size limits and into as many messages as the block limit needs. It also returns
+// how many projects each message lists.
+func digestMessages(digest model.Digest) ([]message, []int) {
+	summary := fmt.Sprintf("Project digest: %d projects, %s to %s", len(digest.Projects),
+		formatDigestTime(digest.From), formatDigestTime(digest.To))
+
+	var sections []string
+	var lines []int
+	var current strings.Builder
+	size, count := 0, 0
+	for i, project := range digest.Projects {
+		line := digestLine(i+1, project)
+		length := len([]rune(line))
+		if size > 0 && size+1+length > maxSectionText {
+			sections = append(sections, current.String())
+			lines = append(lines, count)
+			current.Reset()
+			size, count = 0, 0
+		}
+		if size > 0 {
+			current.WriteString("\n")
+			size++
+		}
+		current.WriteString(line)
+		size += length
+		count++
+	}
+	if current.Len() > 0 {
+		sections = append(sections, current.String())
+		lines = append(lines, count)
+	}
+
+	var messages []message
+	var projects []int
+	for start := 0; start < len(sections); start += maxDigestSections {
+		end := min(start+maxDigestSections, len(sections))
+		header := "*" + summary + "*"
+		if len(sections) > maxDigestSections {
+			header = fmt.Sprintf("*%s* (%d/%d)", summary, start/maxDigestSections+1, (len(sections)+maxDigestSections-1)/maxDigestSections)
+		}
+		blocks := []block{{Type: "section", Text: ptr(mrkdwn(header, maxSectionText))}}
+		listed := 0
+		for i, section := range sections[start:end] {
+			blocks = append(blocks, block{Type: "section", Text: ptr(mrkdwn(section, maxSectionText))})
+			listed += lines[start+i]
+		}
+		messages = append(messages, message{Text: summary, Blocks: blocks})
+		projects = append(projects, listed)
+	}
+	return messages, projects
+}
+
+func digestLine(n int, project model.ScrapedProject) string {
+	title := escape(truncate(project.Title, maxDigestTitle))
+	if project.Link != "" && len(project.Link) <= maxDigestLink {
+		title = fmt.Sprintf("<%s|%s>", escape(project.Link), title)
+	}
+	line := fmt.Sprintf("%d. *%s* — %s · %s", n, title, escape(truncate(orDash(project.BudgetText), maxDigestTitle)), escape(project.Source))
+	if project.BidsCount != nil {
+		line += fmt.Sprintf(" · %d bids", *project.BidsCount)
+	}
+	return line
+}
+
+func truncate(value string, limit int) string {
+	if runes := []rune(value); len(runes) > limit {
+		return string(runes[:limit-1]) + "…"
+	}
+	return value
+}
+
+func formatDigestTime(t time.Time) string {
+	return t.In(notifiers.Tehran).Format("2006-01-02 15:04")
+}
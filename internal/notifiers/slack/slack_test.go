@@ -0,0 +1,163 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+func TestSendAlertPostsBlocksAndRetriesAfterRateLimit(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		posted   message
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0.05")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	n := New(srv.URL)
+	defer n.Close(context.Background())
+
+	bids := 3
+	project := model.ScrapedProject{
+		Source:     "ponisha",
+		Title:      "Build <API> & docs",
+		Link:       "https://ponisha.ir/project/1",
+		BudgetText: "از ۱۰ تا ۲۰ میلیون تومان",
+		Skills:     []string{"Go", "PostgreSQL"},
+		BidsCount:  &bids,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delivery, err := n.SendAlert(ctx, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := delivery.Wait(ctx); err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 2 {
+		t.Fatalf("got %d requests, want the rate-limited one and a retry", requests)
+	}
+	if len(posted.Blocks) != 2 {
+		t.Fatalf("got %d blocks, want title and fields", len(posted.Blocks))
+	}
+	title := posted.Blocks[0].Text.Text
+	if want := "*<https://ponisha.ir/project/1|Build &lt;API&gt; &amp; docs>*"; title != want {
+		t.Errorf("title block = %q, want %q", title, want)
+	}
+	var fields []string
+	for _, f := range posted.Blocks[1].Fields {
+		fields = append(fields, f.Text)
+	}
+	if got := strings.Join(fields, "|"); !strings.Contains(got, "*Skills*\nGo, PostgreSQL") || !strings.Contains(got, "*Bids*\n3") {
+		t.Errorf("fields = %q", got)
+	}
+	if !strings.Contains(posted.Text, "ponisha") {
+		t.Errorf("fallback text = %q", posted.Text)
+	}
+}
+
+func TestSendAlertFailsOnErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	n := New(srv.URL)
+	defer n.Close(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delivery, err := n.SendAlert(ctx, model.ScrapedProject{Title: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = delivery.Wait(ctx)
+	if err == nil || !strings.Contains(err.Error(), "400 invalid_payload") {
+		t.Fatalf("delivery error = %v, want the 400 response", err)
+	}
+}
+
+func TestSendDigestReportsOnlyTheFailedMessage(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 2 {
+			http.Error(w, "invalid_blocks", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	n := New(srv.URL)
+	defer n.Close(context.Background())
+
+	digest := model.Digest{From: time.Now().Add(-time.Hour), To: time.Now()}
+	for i := range 300 {
+		digest.Projects = append(digest.Projects, model.ScrapedProject{
+			Source: "ponisha",
+			Title:  fmt.Sprintf("%03d %s", i, strings.Repeat("x", 110)),
+			Link:   fmt.Sprintf("https://ponisha.ir/project/%d/%s", i, strings.Repeat("y", 80)),
+		})
+	}
+	messages, projects := digestMessages(digest)
+	if len(messages) < 3 {
+		t.Fatalf("digest fits in %d messages, want at least 3", len(messages))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delivery, err := n.SendDigest(ctx, digest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var partial *notifiers.PartialDigestError
+	if err := delivery.Wait(ctx); !errors.As(err, &partial) {
+		t.Fatalf("delivery error = %v, want a *PartialDigestError", err)
+	}
+	if len(partial.Unsent) != projects[1] || partial.Unsent[0] != projects[0] {
+		t.Errorf("unsent = %d projects from %d, want the second message's %d from %d",
+			len(partial.Unsent), partial.Unsent[0], projects[1], projects[0])
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != len(messages) {
+		t.Errorf("got %d requests, want every message posted once", requests)
+	}
+}
@@ -0,0 +1,86 @@
+package notifiers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Throttle spaces out sends to one channel and retries once when the remote asks the caller
+// to slow down. A Throttle is used from a single queue worker and is not safe for concurrent use.
+type Throttle struct {
+	MinInterval time.Duration
+	// Observe, when set, receives the duration and outcome of the attempt that decided the result.
+	Observe func(duration time.Duration, err error)
+
+	last time.Time
+	hold time.Time
+}
+
+// PostFunc makes one delivery attempt. When the remote rejects it for rate limiting it
+// returns how long to wait, along with an error.
+type PostFunc func(ctx context.Context) (retryAfter time.Duration, err error)
+
+// Do waits until MinInterval has passed since the last send and calls post. When post
+// reports a rate limit it waits retryAfter and calls post once more. retried tells whether
+// the second attempt was made.
+func (t *Throttle) Do(ctx context.Context, post PostFunc) (retried bool, err error) {
+	next := t.last.Add(t.MinInterval)
+	if t.hold.After(next) {
+		next = t.hold
+	}
+	if err := sleep(ctx, time.Until(next)); err != nil {
+		return false, err
+	}
+
+	start := time.Now()
+	retryAfter, err := post(ctx)
+	if err != nil && retryAfter > 0 {
+		if err := sleep(ctx, retryAfter); err != nil {
+			return true, err
+		}
+		retried = true
+		start = time.Now()
+		_, err = post(ctx)
+	}
+	if t.Observe != nil {
+		t.Observe(time.Since(start), err)
+	}
+	if err == nil {
+		t.last = time.Now()
+	}
+	return retried, err
+}
+
+// Hold delays the next send by at least d, for remotes that announce an exhausted rate
+// limit bucket before rejecting a request. Call it from inside a PostFunc.
+func (t *Throttle) Hold(d time.Duration) {
+	if until := time.Now().Add(d); until.After(t.hold) {
+		t.hold = until
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RetryAfter reads a Retry-After header given in seconds (fractions allowed), falling back
+// to fallback when it is missing or unreadable.
+func RetryAfter(header string, fallback time.Duration) time.Duration {
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(header), 64)
+	if err != nil || seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// deadLetterLog appends requests that failed every attempt to a JSON-lines file, so they can
+// be inspected and replayed once the endpoint is fixed. With no path it does nothing; the
+// failure is still logged.
+type deadLetterLog struct {
+	path string
+}
+
+type deadLetter struct {
+	FailedAt time.Time `json:"failedAt"`
+	URL      string    `json:"url"`
+	Error    string    `json:"error"`
+	Payload  Payload   `json:"payload"`
+}
+
+func (l *deadLetterLog) record(url string, payload Payload, cause error) error {
+	if l.path == "" {
+		return nil
+	}
+	line, err := json.Marshal(deadLetter{FailedAt: time.Now().UTC(), URL: url, Error: cause.Error(), Payload: payload})
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(l.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("create dead-letter dir: %w", err)
+		}
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open dead-letter log: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write dead-letter log: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+// SchemaVersion is sent in every payload and in the X-Ponisha-Schema header. Fields may be
+// added within a version; renaming or removing one bumps it.
+const SchemaVersion = "1"
+
+const (
+	EventProjectCreated = "project.created"
+	EventSystemAlert    = "system.alert"
+	EventDigest         = "digest.created"
+)
+
+// Payload is the JSON body of every request.
+type Payload struct {
+	SchemaVersion string `json:"schemaVersion"`
+	Event         string `json:"event"`
+	// ID is also the Idempotency-Key header. It is derived from the project, so the same
+	// project always carries the same key, across retries and across replicas.
+	ID         string    `json:"id"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Run        Run       `json:"run"`
+
+	Project *Project     `json:"project,omitempty"`
+	Alert   *SystemAlert `json:"alert,omitempty"`
+	Digest  *Digest      `json:"digest,omitempty"`
+}
+
+// Run identifies the scrape run and the process that produced the event.
+type Run struct {
+	ID      string `json:"id,omitempty"`
+	Replica string `json:"replica,omitempty"`
+}
+
+type Project struct {
+	Source          string            `json:"source"`
+	ExternalID      string            `json:"externalId"`
+	Title           string            `json:"title"`
+	Link            string            `json:"link"`
+	Description     string            `json:"description"`
+	Budget          Budget            `json:"budget"`
+	Skills          []string          `json:"skills"`
+	ApprovedAt      string            `json:"approvedAt,omitempty"`
+	BiddingClosedAt string            `json:"biddingClosedAt,omitempty"`
+	BidsCount       *int              `json:"bidsCount"`
+	Annotations     map[string]string `json:"annotations,omitempty"`
+}
+
+// Budget amounts are in toman; Text is the provider's own wording.
+type Budget struct {
+	Text string `json:"text"`
+	Min  int64  `json:"min"`
+	Max  int64  `json:"max"`
+}
+
+// Digest lists the projects collected between From and To, in digest order.
+type Digest struct {
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Projects []Project `json:"projects"`
+}
+
+type SystemAlert struct {
+	Kind    string `json:"kind"`
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+func projectPayload(project model.ScrapedProject, run Run, now time.Time) Payload {
+	body := projectBody(project)
+	return Payload{
+		SchemaVersion: SchemaVersion,
+		Event:         EventProjectCreated,
+		ID:            projectKey(project),
+		OccurredAt:    now.UTC(),
+		Run:           run,
+		Project:       &body,
+	}
+}
+
+// digestPayload's ID is derived from the projects it lists, so a digest retried in a later
+// window with nothing new keeps its key.
+func digestPayload(digest model.Digest, run Run, now time.Time) Payload {
+	body := Digest{From: digest.From.UTC(), To: digest.To.UTC(), Projects: make([]Project, len(digest.Projects))}
+	h := sha256.New()
+	h.Write([]byte(EventDigest))
+	for i, project := range digest.Projects {
+		body.Projects[i] = projectBody(project)
+		h.Write([]byte("\x00" + projectKey(project)))
+	}
+	return Payload{
+		SchemaVersion: SchemaVersion,
+		Event:         EventDigest,
+		ID:            hex.EncodeToString(h.Sum(nil)[:16]),
+		OccurredAt:    now.UTC(),
+		Run:           run,
+		Digest:        &body,
+	}
+}
+
+func projectBody(project model.ScrapedProject) Project {
+	skills := project.Skills
+	if skills == nil {
+		skills = []string{}
+	}
+	return Project{
+		Source:          project.Source,
+		ExternalID:      project.ExternalID,
+		Title:           project.Title,
+		Link:            project.Link,
+		Description:     project.Description,
+		Budget:          Budget{Text: project.BudgetText, Min: project.AmountMin, Max: project.AmountMax},
+		Skills:          skills,
+		ApprovedAt:      project.ApprovedAt,
+		BiddingClosedAt: project.BiddingClosedAt,
+		BidsCount:       project.BidsCount,
+		Annotations:     project.Annotations,
+	}
+}
+
+func systemAlertPayload(alert model.SystemAlert, run Run, now time.Time) Payload {
+	return Payload{
+		SchemaVersion: SchemaVersion,
+		Event:         EventSystemAlert,
+		ID:            randomKey(),
+		OccurredAt:    now.UTC(),
+		Run:           run,
+		Alert:         &SystemAlert{Kind: string(alert.Kind), Source: alert.Source, Message: alert.Message},
+	}
+}
+
+func projectKey(project model.ScrapedProject) string {
+	sum := sha256.Sum256([]byte(EventProjectCreated + "\x00" + project.Source + "\x00" + project.ExternalID))
+	return hex.EncodeToString(sum[:16])
+}
+
+func randomKey() string {
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+	return hex.EncodeToString(key)
+}
@@ -0,0 +1,297 @@
+// Package webhook POSTs alerts as versioned, signed JSON to an HTTP endpoint for downstream
+// tooling (CRMs, automation, internal bots).
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ponisha-go/internal/logging"
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+	"ponisha-go/internal/tracing"
+)
+
+// Request headers. The signature is "sha256=" followed by the hex HMAC-SHA256, keyed with the
+// shared secret, of the timestamp header value, a ".", and the raw body.
+const (
+	HeaderSignature   = "X-Ponisha-Signature"
+	HeaderTimestamp   = "X-Ponisha-Timestamp"
+	HeaderEvent       = "X-Ponisha-Event"
+	HeaderSchema      = "X-Ponisha-Schema"
+	HeaderAttempt     = "X-Ponisha-Attempt"
+	HeaderIdempotency = "Idempotency-Key"
+)
+
+// Notifier delivers alerts to one endpoint, retrying failed requests with exponential backoff
+// and recording requests that never succeeded in the dead-letter log.
+type Notifier struct {
+	url     string
+	secret  []byte
+	replica string
+
+	client      *http.Client
+	queue       *notifiers.Queue[Payload]
+	queueSize   int
+	maxAttempts int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	deadLetter  *deadLetterLog
+
+	metrics Metrics
+}
+
+// Metrics receives the latency and outcome of every delivered message.
+type Metrics interface {
+	ObserveSend(notifier string, duration time.Duration, err error)
+}
+
+type Option func(*Notifier)
+
+func WithMetrics(metrics Metrics) Option {
+	return func(n *Notifier) {
+		n.metrics = metrics
+	}
+}
+
+// WithHTTPClient replaces the default client used to call the endpoint.
+func WithHTTPClient(client *http.Client) Option {
+	return func(n *Notifier) {
+		n.client = client
+	}
+}
+
+// WithQueueSize sets how many alerts may wait to be sent (100 by default).
+func WithQueueSize(size int) Option {
+	return func(n *Notifier) {
+		n.queueSize = size
+	}
+}
+
+// WithReplica names this process in the payload's run metadata.
+func WithReplica(id string) Option {
+	return func(n *Notifier) {
+		n.replica = id
+	}
+}
+
+// WithRetry sets how many attempts a request gets (5 by default) and the backoff between
+// them, which doubles from base up to max (1s and 1m by default).
+func WithRetry(maxAttempts int, base, max time.Duration) Option {
+	return func(n *Notifier) {
+		n.maxAttempts = maxAttempts
+		n.backoffBase = base
+		n.backoffMax = max
+	}
+}
+
+// WithDeadLetterFile appends requests that failed every attempt to path as JSON lines.
+// Without it they are only logged.
+func WithDeadLetterFile(path string) Option {
+	return func(n *Notifier) {
+		n.deadLetter = &deadLetterLog{path: path}
+	}
+}
+
+// New returns a Notifier posting to url and signing with secret. An empty secret sends
+// unsigned requests.
+func New(url, secret string, options ...Option) *Notifier {
+	n := &Notifier{
+		url:         url,
+		secret:      []byte(secret),
+		client:      &http.Client{Timeout: 15 * time.Second},
+		queueSize:   100,
+		maxAttempts: 5,
+		backoffBase: time.Second,
+		backoffMax:  time.Minute,
+		deadLetter:  &deadLetterLog{},
+	}
+	for _, option := range options {
+		option(n)
+	}
+	if n.maxAttempts < 1 {
+		n.maxAttempts = 1
+	}
+
+	n.queue = notifiers.NewQueue(n.queueSize, n.send)
+	return n
+}
+
+// SendAlert queues the project, waiting for room in the queue until ctx is done. The returned
+// Delivery completes once the endpoint accepted it or every attempt failed.
+func (n *Notifier) SendAlert(ctx context.Context, project model.ScrapedProject) (*notifiers.Delivery, error) {
+	return n.enqueue(ctx, projectPayload(project, n.run(ctx), time.Now()))
+}
+
+func (n *Notifier) SendSystemAlert(ctx context.Context, alert model.SystemAlert) (*notifiers.Delivery, error) {
+	return n.enqueue(ctx, systemAlertPayload(alert, n.run(ctx), time.Now()))
+}
+
+// SendDigest queues the digest as a single digest.created event.
+func (n *Notifier) SendDigest(ctx context.Context, digest model.Digest) (*notifiers.Delivery, error) {
+	return n.enqueue(ctx, digestPayload(digest, n.run(ctx), time.Now()))
+}
+
+// QueueDepth is the number of alerts waiting to be sent.
+func (n *Notifier) QueueDepth() int {
+	return n.queue.Len()
+}
+
+// Close stops accepting alerts and waits until the queued ones have been sent.
+func (n *Notifier) Close(ctx context.Context) error {
+	return n.queue.Close(ctx)
+}
+
+func (n *Notifier) run(ctx context.Context) Run {
+	return Run{ID: logging.RunID(ctx), Replica: n.replica}
+}
+
+func (n *Notifier) enqueue(ctx context.Context, payload Payload) (*notifiers.Delivery, error) {
+	delivery, err := n.queue.Enqueue(ctx, payload)
+	if err != nil {
+		slog.WarnContext(ctx, "webhook not queued", "error", err)
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	return delivery, nil
+}
+
+func (n *Notifier) send(ctx context.Context, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= n.maxAttempts; attempt++ {
+		start := time.Now()
+		retryAfter, err := n.post(ctx, payload, body, attempt)
+		n.observe(time.Since(start), err)
+		if err == nil {
+			slog.InfoContext(ctx, "webhook delivered", "event", payload.Event, "id", payload.ID, "attempt", attempt)
+			return nil
+		}
+		lastErr = err
+
+		var permanent permanentError
+		if errors.As(err, &permanent) || attempt == n.maxAttempts {
+			break
+		}
+		wait := max(n.backoff(attempt), retryAfter)
+		slog.WarnContext(ctx, "webhook attempt failed; retrying", "error", err, "attempt", attempt, "retry_in", wait)
+		if err := sleep(ctx, wait); err != nil {
+			lastErr = err
+			break
+		}
+	}
+
+	slog.ErrorContext(ctx, "webhook delivery failed", "error", lastErr, "event", payload.Event, "id", payload.ID)
+	if err := n.deadLetter.record(n.url, payload, lastErr); err != nil {
+		slog.ErrorContext(ctx, "webhook dead-letter write failed", "error", err)
+	}
+	return lastErr
+}
+
+func (n *Notifier) observe(duration time.Duration, err error) {
+	if n.metrics != nil {
+		n.metrics.ObserveSend("webhook", duration, err)
+	}
+}
+
+// backoff is base·2^(attempt-1), capped at max, with up to 20% random jitter so retries from
+// several replicas spread out.
+func (n *Notifier) backoff(attempt int) time.Duration {
+	wait := n.backoffBase << (attempt - 1)
+	if wait <= 0 || wait > n.backoffMax {
+		wait = n.backoffMax
+	}
+	return wait + time.Duration(rand.Int64N(int64(wait)/5+1))
+}
+
+func (n *Notifier) post(ctx context.Context, payload Payload, body []byte, attempt int) (retryAfter time.Duration, err error) {
+	ctx, span := tracing.Start(ctx, "webhook.post")
+	defer func() { tracing.End(span, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, permanentError{err}
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ponisha-go-webhook/"+SchemaVersion)
+	req.Header.Set(HeaderEvent, payload.Event)
+	req.Header.Set(HeaderSchema, SchemaVersion)
+	req.Header.Set(HeaderIdempotency, payload.ID)
+	req.Header.Set(HeaderAttempt, strconv.Itoa(attempt))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if len(n.secret) > 0 {
+		req.Header.Set(HeaderSignature, Sign(n.secret, timestamp, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		return notifiers.RetryAfter(resp.Header.Get("Retry-After"), 0), statusError(resp.StatusCode, reply)
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout:
+		return 0, statusError(resp.StatusCode, reply)
+	default:
+		// Other client errors will not go away by sending the same request again.
+		return 0, permanentError{statusError(resp.StatusCode, reply)}
+	}
+}
+
+// Sign returns the signature header value for body sent with the given timestamp header.
+// Receivers recompute it with the shared secret and compare with hmac.Equal.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func statusError(code int, reply []byte) error {
+	if text := strings.TrimSpace(string(reply)); text != "" {
+		return fmt.Errorf("webhook error: %d %s", code, text)
+	}
+	return fmt.Errorf("webhook error: %d", code)
+}
+
+// permanentError marks a failure that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
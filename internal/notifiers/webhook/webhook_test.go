@@ -0,0 +1,155 @@
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+// endpoint answers each request with the next status in statuses (200 once they run out)
+// and records what it received.
+type endpoint struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.requests = append(e.requests, r)
+	e.bodies = append(e.bodies, body)
+	status := http.StatusOK
+	if n := len(e.requests); n <= len(e.statuses) {
+		status = e.statuses[n-1]
+	}
+	w.WriteHeader(status)
+}
+
+func deliver(t *testing.T, n *Notifier, project model.ScrapedProject) error {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delivery, err := n.SendAlert(ctx, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return delivery.Wait(ctx)
+}
+
+func TestSendAlertSignsAndRetries(t *testing.T) {
+	e := &endpoint{statuses: []int{http.StatusInternalServerError, http.StatusServiceUnavailable}}
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	secret := "s3cret"
+	n := New(srv.URL, secret, WithRetry(3, time.Millisecond, 5*time.Millisecond), WithReplica("replica-1"))
+	defer n.Close(context.Background())
+
+	project := model.ScrapedProject{Source: "ponisha", ExternalID: "42", Title: "API", AmountMax: 20_000_000}
+	if err := deliver(t, n, project); err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.requests) != 3 {
+		t.Fatalf("got %d requests, want 2 failures and a success", len(e.requests))
+	}
+	key := e.requests[0].Header.Get(HeaderIdempotency)
+	for i, r := range e.requests {
+		if got := r.Header.Get(HeaderAttempt); got != strconv.Itoa(i+1) {
+			t.Errorf("request %d: attempt header %q", i, got)
+		}
+		if got := r.Header.Get(HeaderIdempotency); got != key {
+			t.Errorf("request %d: idempotency key %q, want %q on every attempt", i, got, key)
+		}
+		if got := r.Header.Get(HeaderEvent); got != EventProjectCreated {
+			t.Errorf("request %d: event %q", i, got)
+		}
+		want := Sign([]byte(secret), r.Header.Get(HeaderTimestamp), e.bodies[i])
+		if got := r.Header.Get(HeaderSignature); !hmac.Equal([]byte(got), []byte(want)) {
+			t.Errorf("request %d: signature %q, want %q", i, got, want)
+		}
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(e.bodies[2], &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.ID != key || payload.SchemaVersion != SchemaVersion || payload.Run.Replica != "replica-1" {
+		t.Errorf("payload = %+v", payload)
+	}
+	if payload.Project == nil || payload.Project.ExternalID != "42" || payload.Project.Budget.Max != 20_000_000 {
+		t.Errorf("payload project = %+v", payload.Project)
+	}
+}
+
+func TestSendAlertDeadLettersFailedRequests(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		attempts int
+	}{
+		{"client error is not retried", []int{http.StatusBadRequest}, 1},
+		{"server errors until attempts run out", []int{500, 502, 504}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &endpoint{statuses: tt.statuses}
+			srv := httptest.NewServer(e)
+			defer srv.Close()
+
+			path := filepath.Join(t.TempDir(), "dead", "letters.jsonl")
+			n := New(srv.URL, "", WithRetry(3, time.Millisecond, 5*time.Millisecond), WithDeadLetterFile(path))
+			defer n.Close(context.Background())
+
+			if err := deliver(t, n, model.ScrapedProject{Source: "karlancer", ExternalID: "7"}); err == nil {
+				t.Fatal("delivery succeeded, want an error")
+			}
+			e.mu.Lock()
+			if len(e.requests) != tt.attempts {
+				t.Errorf("got %d requests, want %d", len(e.requests), tt.attempts)
+			}
+			if got := e.requests[0].Header.Get(HeaderSignature); got != "" {
+				t.Errorf("unsigned request carries signature %q", got)
+			}
+			e.mu.Unlock()
+
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			var letters []deadLetter
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				var letter deadLetter
+				if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+					t.Fatal(err)
+				}
+				letters = append(letters, letter)
+			}
+			if len(letters) != 1 {
+				t.Fatalf("got %d dead letters, want 1", len(letters))
+			}
+			if letters[0].URL != srv.URL || letters[0].Error == "" || letters[0].Payload.Project.ExternalID != "7" {
+				t.Errorf("dead letter = %+v", letters[0])
+			}
+		})
+	}
+}
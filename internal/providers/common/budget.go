@@ -0,0 +1,169 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseBudgetText reads a rendered budget such as "۵۰٬۰۰۰٬۰۰۰ تا ۱۰۰٬۰۰۰٬۰۰۰ تومان" into toman amounts.
+// A single amount is treated as an upper bound after "تا", a lower bound after "از", and a fixed price otherwise.
+func ParseBudgetText(text string) (int64, int64, bool) {
+	normalized := NormalizeDigits(text)
+	amounts, positions := scanAmounts(normalized)
+	if len(amounts) == 0 {
+		return 0, 0, false
+	}
+
+	if strings.Contains(normalized, "ریال") && !strings.Contains(normalized, "تومان") {
+		for i := range amounts {
+			amounts[i] /= 10
+		}
+	}
+
+	if len(amounts) >= 2 {
+		amountMin, amountMax := amounts[0], amounts[1]
+		if amountMin > amountMax {
+			amountMin, amountMax = amountMax, amountMin
+		}
+		return amountMin, amountMax, true
+	}
+
+	prefix := normalized[:positions[0]]
+	switch {
+	case strings.Contains(prefix, "تا"):
+		return 0, amounts[0], true
+	case strings.Contains(prefix, "از"):
+		return amounts[0], 0, true
+	default:
+		return amounts[0], amounts[0], true
+	}
+}
+
+// NormalizeDigits turns Persian and Arabic-Indic digits into ASCII ones.
+func NormalizeDigits(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '۰' && r <= '۹':
+			return '0' + (r - '۰')
+		case r >= '٠' && r <= '٩':
+			return '0' + (r - '٠')
+		}
+		return r
+	}, text)
+}
+
+// scanAmounts returns every number in text with thousands separators removed and word
+// multipliers applied, along with the byte offset where each number starts. A decimal point
+// followed by a digit starts a fraction, so "۱.۵ میلیون" is 1,500,000. A number without
+// a unit takes the unit of the number after it when it is smaller than that unit, so
+// "از ۱۰ تا ۲۰ میلیون" reads as 10 and 20 million while "۵۰٬۰۰۰٬۰۰۰ تا ۱۰۰ میلیون" is left alone.
+func scanAmounts(text string) ([]int64, []int) {
+	var numbers []number
+	var multipliers []int64
+	var positions []int
+
+	runes := []rune(text)
+	offset := 0
+	for i := 0; i < len(runes); {
+		if !isDigit(runes[i]) {
+			offset += len(string(runes[i]))
+			i++
+			continue
+		}
+
+		start := offset
+		var whole, fraction strings.Builder
+		inFraction := false
+		for ; i < len(runes); i++ {
+			r := runes[i]
+			nextIsDigit := i+1 < len(runes) && isDigit(runes[i+1])
+			if isDigit(r) {
+				if inFraction {
+					fraction.WriteRune(r)
+				} else {
+					whole.WriteRune(r)
+				}
+			} else if isDecimalPoint(r) && !inFraction && nextIsDigit {
+				inFraction = true
+			} else if !isThousandsSeparator(r) || inFraction || !nextIsDigit {
+				break
+			}
+			offset += len(string(r))
+		}
+
+		n, ok := parseNumber(whole.String(), fraction.String())
+		if !ok {
+			continue
+		}
+		numbers = append(numbers, n)
+		multipliers = append(multipliers, multiplierAfter(string(runes[i:])))
+		positions = append(positions, start)
+	}
+
+	amounts := make([]int64, len(numbers))
+	for i := len(numbers) - 1; i >= 0; i-- {
+		if i+1 < len(numbers) {
+			if next := multipliers[i+1]; multipliers[i] == 1 && next > 1 && numbers[i].times(1) < next {
+				multipliers[i] = next
+			}
+		}
+		amounts[i] = numbers[i].times(multipliers[i])
+	}
+	return amounts, positions
+}
+
+// number is a decimal read from budget text: whole plus fraction/scale.
+type number struct {
+	whole, fraction, scale int64
+}
+
+func parseNumber(whole, fraction string) (number, bool) {
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return number{}, false
+	}
+	n := number{whole: w, scale: 1}
+	if fraction == "" {
+		return n, true
+	}
+	// Digits past the ninth are below one toman even in billions.
+	fraction = fraction[:min(len(fraction), 9)]
+	if n.fraction, err = strconv.ParseInt(fraction, 10, 64); err != nil {
+		return number{}, false
+	}
+	for range fraction {
+		n.scale *= 10
+	}
+	return n, true
+}
+
+// times scales the number by multiplier, dropping anything below one toman.
+func (n number) times(multiplier int64) int64 {
+	return n.whole*multiplier + n.fraction*multiplier/n.scale
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// isDecimalPoint matches the ASCII point and the Arabic decimal separator '٫'.
+func isDecimalPoint(r rune) bool {
+	return r == '.' || r == '٫'
+}
+
+func isThousandsSeparator(r rune) bool {
+	return r == ',' || r == '٬' || r == '،'
+}
+
+func multiplierAfter(rest string) int64 {
+	rest = strings.TrimSpace(rest)
+	switch {
+	case strings.HasPrefix(rest, "میلیارد"):
+		return 1_000_000_000
+	case strings.HasPrefix(rest, "میلیون"):
+		return 1_000_000
+	case strings.HasPrefix(rest, "هزار"):
+		return 1_000
+	}
+	return 1
+}
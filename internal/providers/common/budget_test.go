@@ -0,0 +1,32 @@
+package common
+
+import "testing"
+
+func TestParseBudgetText(t *testing.T) {
+	tests := []struct {
+		text     string
+		min, max int64
+	}{
+		{"۵۰٬۰۰۰٬۰۰۰ تا ۱۰۰٬۰۰۰٬۰۰۰ تومان", 50_000_000, 100_000_000},
+		{"از ۱۰ تا ۲۰ میلیون تومان", 10_000_000, 20_000_000},
+		{"۵۰۰ تا ۸۰۰ هزار تومان", 500_000, 800_000},
+		{"۵۰۰ هزار تا ۲ میلیون تومان", 500_000, 2_000_000},
+		{"۵۰٬۰۰۰٬۰۰۰ تا ۱۰۰ میلیون تومان", 50_000_000, 100_000_000},
+		{"تا ۳۰ میلیون تومان", 0, 30_000_000},
+		{"از ۱ میلیارد تومان", 1_000_000_000, 0},
+		{"۲۰۰٬۰۰۰٬۰۰۰ ریال", 20_000_000, 20_000_000},
+		{"۱.۵ میلیون تومان", 1_500_000, 1_500_000},
+		{"۱٫۵ تا ۲٫۷۵ میلیون تومان", 1_500_000, 2_750_000},
+		{"از ۲.۵ میلیارد تومان", 2_500_000_000, 0},
+		{"تا ۱۲.۵ هزار تومان", 0, 12_500},
+		{"۵۰۰ هزار تا ۱.۲ میلیون تومان", 500_000, 1_200_000},
+		{"۱٬۲۰۰٫۵ تومان", 1_200, 1_200},
+		{"بودجه: ۳ میلیون.", 3_000_000, 3_000_000},
+	}
+	for _, tt := range tests {
+		min, max, ok := ParseBudgetText(tt.text)
+		if !ok || min != tt.min || max != tt.max {
+			t.Errorf("ParseBudgetText(%q) = %d, %d, %v; want %d, %d", tt.text, min, max, ok, tt.min, tt.max)
+		}
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"strings"
 )
 
+// DefaultUserAgent is the User-Agent providers send, and the one robots.txt rules are
+// matched against.
+const DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
+
 func FormatBudgetText(amountMin, amountMax int64) string {
 	if amountMin > 0 && amountMax > 0 {
 		return "از " + formatToman(amountMin) + " تا " + formatToman(amountMax) + " تومان"
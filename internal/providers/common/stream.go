@@ -0,0 +1,76 @@
+package common
+
+import (
+	"context"
+	"net/http"
+
+	"ponisha-go/internal/model"
+)
+
+// SendPage delivers page unless ctx is cancelled first.
+func SendPage(ctx context.Context, pages chan<- model.ScrapePage, page model.ScrapePage) error {
+	select {
+	case pages <- page:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// CollectPages drains a page stream into a single result. Failed pages are recorded in
+// PageErrors, and pages received before the stream fails are kept alongside the error.
+func CollectPages(ctx context.Context, stream func(context.Context, chan<- model.ScrapePage) error) (model.ScrapeResult, error) {
+	pages := make(chan model.ScrapePage)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- stream(ctx, pages)
+		close(pages)
+	}()
+
+	var result model.ScrapeResult
+	for page := range pages {
+		if page.Err != nil {
+			result.PageErrors = append(result.PageErrors, model.PageError{Page: page.Number, Err: page.Err})
+			continue
+		}
+		result.Projects = append(result.Projects, page.Projects...)
+		result.Diagnostics.Merge(page.Diagnostics)
+	}
+	return result, <-errCh
+}
+
+// RequestGate is consulted before every page request, e.g. to apply robots.txt rules and
+// crawl delays. It returns an error when the request must not be made.
+type RequestGate interface {
+	Acquire(ctx context.Context, rawURL string) error
+}
+
+// ResponseObserver records the outcome of every provider HTTP request; err is set when no
+// response arrived.
+type ResponseObserver interface {
+	ObserveResponse(source string, code int, err error)
+}
+
+type unobservedKey struct{}
+
+// Unobserved marks ctx so requests made under it are not reported to the ResponseObserver;
+// dry runs use it to keep provider metrics untouched.
+func Unobserved(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unobservedKey{}, true)
+}
+
+// Observe reports a request's outcome to obs unless obs is nil or ctx is Unobserved.
+func Observe(ctx context.Context, obs ResponseObserver, source string, resp *http.Response, err error) {
+	if obs == nil || ctx.Value(unobservedKey{}) != nil {
+		return
+	}
+	obs.ObserveResponse(source, StatusCode(resp), err)
+}
+
+// StatusCode is resp's status code, or 0 when there is no response.
+func StatusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
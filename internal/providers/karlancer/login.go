@@ -0,0 +1,23 @@
+package karlancer
+
+import (
+	"context"
+	"net/http"
+
+	"ponisha-go/internal/providers/session"
+)
+
+const DefaultLoginURL = "https://www.karlancer.com/api/auth/login"
+
+// Login returns the Karlancer login flow for loginURL, or DefaultLoginURL when it is empty.
+func Login(loginURL string) session.LoginFunc {
+	if loginURL == "" {
+		loginURL = DefaultLoginURL
+	}
+	return func(ctx context.Context, client *http.Client, creds session.Credentials) (string, error) {
+		return session.PostJSONLogin(ctx, client, loginURL, map[string]string{
+			"email":    creds.Username,
+			"password": creds.Password,
+		})
+	}
+}
@@ -4,24 +4,65 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"sync"
+	"net/url"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
+	"ponisha-go/internal/logging"
 	"ponisha-go/internal/model"
 	"ponisha-go/internal/providers/common"
+	"ponisha-go/internal/tracing"
 )
 
 type KarlancerScraper struct {
 	client *http.Client
 	base   string
+	gate   common.RequestGate
+	obs    common.ResponseObserver
+	ua     string
 }
 
-func NewScraper(client *http.Client) *KarlancerScraper {
-	return &KarlancerScraper{client: client, base: "https://www.karlancer.com/api/publics/search/projects"}
+type Option func(*KarlancerScraper)
+
+// WithBaseURL points the scraper at another search endpoint, e.g. a local stand-in.
+func WithBaseURL(base string) Option {
+	return func(k *KarlancerScraper) {
+		k.base = base
+	}
+}
+
+// WithRobots makes every page request pass through gate first.
+func WithRobots(gate common.RequestGate) Option {
+	return func(k *KarlancerScraper) {
+		k.gate = gate
+	}
+}
+
+// WithMetrics reports the status of every page request to obs.
+func WithMetrics(obs common.ResponseObserver) Option {
+	return func(k *KarlancerScraper) {
+		k.obs = obs
+	}
+}
+
+// WithUserAgent replaces common.DefaultUserAgent on page requests.
+func WithUserAgent(userAgent string) Option {
+	return func(k *KarlancerScraper) {
+		k.ua = userAgent
+	}
+}
+
+func NewScraper(client *http.Client, options ...Option) *KarlancerScraper {
+	k := &KarlancerScraper{client: client, base: "https://www.karlancer.com/api/publics/search/projects", ua: common.DefaultUserAgent}
+	for _, option := range options {
+		option(k)
+	}
+	return k
 }
 
 func (k *KarlancerScraper) Source() string {
@@ -63,145 +104,194 @@ type karlancerProject struct {
 	} `json:"skills"`
 }
 
-func (k *KarlancerScraper) Scrape(ctx context.Context) ([]model.ScrapedProject, error) {
-	log.Printf("[karlancer] page 1/1")
-	firstPage, lastPage, err := k.fetchPage(ctx, 1)
+type karlancerPage struct {
+	projects    []model.ScrapedProject
+	lastPage    int
+	diagnostics model.ScrapeDiagnostics
+}
+
+func (p karlancerPage) scrapePage(number int) model.ScrapePage {
+	return model.ScrapePage{Source: "karlancer", Number: number, Projects: p.projects, Diagnostics: p.diagnostics}
+}
+
+func (k *KarlancerScraper) Scrape(ctx context.Context) (model.ScrapeResult, error) {
+	return common.CollectPages(ctx, k.Stream)
+}
+
+// Stream sends every API page on pages as soon as it is decoded. Sends block, so a slow
+// consumer throttles fetching. Only a failed first page aborts the stream; later failures are
+// sent as pages with Err set.
+func (k *KarlancerScraper) Stream(ctx context.Context, pages chan<- model.ScrapePage) error {
+	ctx = logging.WithSource(ctx, "karlancer")
+	firstCtx := logging.WithPage(ctx, 1)
+	slog.DebugContext(firstCtx, "fetching page")
+	first, err := k.fetchPage(firstCtx, 1)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	log.Printf("[karlancer] page 1 found %d items (total pages: %d)", len(firstPage), lastPage)
-	if lastPage <= 1 {
-		return firstPage, nil
+	slog.InfoContext(firstCtx, "page fetched", "items", len(first.projects), "total_pages", first.lastPage)
+	if err := common.SendPage(ctx, pages, first.scrapePage(1)); err != nil {
+		return err
+	}
+	if first.lastPage <= 1 {
+		return nil
 	}
-
-	projects := make([]model.ScrapedProject, 0, len(firstPage)*(lastPage))
-	projects = append(projects, firstPage...)
 
 	group, gctx := errgroup.WithContext(ctx)
 	group.SetLimit(4)
 
-	var mu sync.Mutex
-	for page := 2; page <= lastPage; page++ {
+	for page := 2; page <= first.lastPage; page++ {
 		current := page
 		group.Go(func() error {
-			log.Printf("[karlancer] page %d/%d", current, lastPage)
-			items, _, err := k.fetchPage(gctx, current)
+			pageCtx := logging.WithPage(gctx, current)
+			slog.DebugContext(pageCtx, "fetching page", "total_pages", first.lastPage)
+			pageResult, err := k.fetchPage(pageCtx, current)
 			if err != nil {
-				return err
+				slog.WarnContext(pageCtx, "page failed", "error", err)
+				return common.SendPage(gctx, pages, model.ScrapePage{Source: "karlancer", Number: current, Err: err})
 			}
-			log.Printf("[karlancer] page %d found %d items (total pages: %d)", current, len(items), lastPage)
-			mu.Lock()
-			projects = append(projects, items...)
-			mu.Unlock()
-			return nil
+			slog.InfoContext(pageCtx, "page fetched", "items", len(pageResult.projects), "total_pages", first.lastPage)
+			return common.SendPage(gctx, pages, pageResult.scrapePage(current))
 		})
 	}
 
-	if err := group.Wait(); err != nil {
-		return projects, err
-	}
+	return group.Wait()
+}
 
-	return projects, nil
+func (k *KarlancerScraper) pageURL(page int) (string, error) {
+	u, err := url.Parse(k.base)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("page", fmt.Sprintf("%d", page))
+	q.Set("order", "newest")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
 }
 
-func (k *KarlancerScraper) fetchPage(ctx context.Context, page int) ([]model.ScrapedProject, int, error) {
+func (k *KarlancerScraper) fetchPage(ctx context.Context, page int) (result karlancerPage, err error) {
+	ctx, span := tracing.Start(ctx, "karlancer fetchPage", trace.WithAttributes(attribute.Int("page", page)))
+	defer func() {
+		span.SetAttributes(attribute.Int("items", len(result.projects)))
+		tracing.End(span, err)
+	}()
+
+	pageURL, err := k.pageURL(page)
+	if err != nil {
+		return karlancerPage{}, err
+	}
+	if k.gate != nil {
+		if err := k.gate.Acquire(ctx, pageURL); err != nil {
+			return karlancerPage{}, err
+		}
+	}
+
 	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, k.base, nil)
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, pageURL, nil)
 	if err != nil {
-		return nil, 0, err
+		return karlancerPage{}, err
 	}
 
-	q := req.URL.Query()
-	q.Set("page", fmt.Sprintf("%d", page))
-	q.Set("order", "newest")
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("User-Agent", k.ua)
 	req.Header.Set("Accept", "application/json, text/plain, */*")
 	req.Header.Set("Referer", "https://www.karlancer.com/")
 
 	resp, err := k.client.Do(req)
+	common.Observe(reqCtx, k.obs, "karlancer", resp, err)
 	if err != nil {
-		return nil, 0, err
+		return karlancerPage{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, 0, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		return karlancerPage{}, fmt.Errorf("unexpected status: %d", resp.StatusCode)
 	}
 
 	decoder := json.NewDecoder(resp.Body)
 	decoder.UseNumber()
 	var payload karlancerResponse
 	if err := decoder.Decode(&payload); err != nil {
-		return nil, 0, err
+		return karlancerPage{}, err
 	}
 
+	result = karlancerPage{diagnostics: model.ScrapeDiagnostics{Pages: 1}}
 	data := payload.Data
 	if data == nil {
-		return nil, 0, nil
+		result.diagnostics.PagesWithoutPayload = 1
+		return result, nil
 	}
 
-	projects := make([]model.ScrapedProject, 0, len(data.Data))
+	result.lastPage = data.LastPage
+	result.projects = make([]model.ScrapedProject, 0, len(data.Data))
 	for _, p := range data.Data {
-		id := pickID(p.ID, p.UUID, p.AltID)
-		if id == "" {
+		result.diagnostics.ItemsSeen++
+		project, reason := parseProject(p)
+		if reason != "" {
+			result.diagnostics.Skip(reason)
 			continue
 		}
+		result.diagnostics.ItemsParsed++
+		result.projects = append(result.projects, project)
+	}
 
-		amountMin := common.ToInt64(p.MinBudget)
-		if amountMin == 0 {
-			amountMin = common.ToInt64(p.BudgetFrom)
-		}
-		if amountMin == 0 {
-			amountMin = common.ToInt64(p.AmountMin)
-		}
-		if amountMin == 0 {
-			amountMin = common.ToInt64(p.PriceMin)
-		}
+	return result, nil
+}
 
-		amountMax := common.ToInt64(p.MaxBudget)
-		if amountMax == 0 {
-			amountMax = common.ToInt64(p.BudgetTo)
-		}
-		if amountMax == 0 {
-			amountMax = common.ToInt64(p.AmountMax)
-		}
-		if amountMax == 0 {
-			amountMax = common.ToInt64(p.PriceMax)
-		}
+func parseProject(p karlancerProject) (model.ScrapedProject, model.SkipReason) {
+	id := pickID(p.ID, p.UUID, p.AltID)
+	if id == "" {
+		return model.ScrapedProject{}, model.SkipMissingID
+	}
 
-		if !model.IsAboveThreshold(amountMin, amountMax) {
-			continue
-		}
+	amountMin := common.ToInt64(p.MinBudget)
+	if amountMin == 0 {
+		amountMin = common.ToInt64(p.BudgetFrom)
+	}
+	if amountMin == 0 {
+		amountMin = common.ToInt64(p.AmountMin)
+	}
+	if amountMin == 0 {
+		amountMin = common.ToInt64(p.PriceMin)
+	}
 
-		linkSlug := p.URL
-		if linkSlug == "" {
-			linkSlug = id
-		}
+	amountMax := common.ToInt64(p.MaxBudget)
+	if amountMax == 0 {
+		amountMax = common.ToInt64(p.BudgetTo)
+	}
+	if amountMax == 0 {
+		amountMax = common.ToInt64(p.AmountMax)
+	}
+	if amountMax == 0 {
+		amountMax = common.ToInt64(p.PriceMax)
+	}
 
-		project := model.ScrapedProject{
-			Source:          "karlancer",
-			ExternalID:      id,
-			Title:           pickTitle(p.Title),
-			Link:            fmt.Sprintf("https://www.karlancer.com/projects/%s", linkSlug),
-			BudgetText:      common.FormatBudgetText(amountMin, amountMax),
-			AmountMin:       amountMin,
-			AmountMax:       amountMax,
-			Description:     p.Description,
-			ApprovedAt:      pickString(p.PublishedAt, p.ApprovedAt),
-			BiddingClosedAt: pickString(p.ExpiredAt, p.ExpiredAlt),
-			BidsCount:       pickIntPtr(p.BidsCount, p.BidsAlt),
-			Skills:          collectSkills(p.Skills),
-		}
+	if amountMin <= 0 && amountMax <= 0 {
+		return model.ScrapedProject{}, model.SkipNoBudget
+	}
 
-		projects = append(projects, project)
+	linkSlug := p.URL
+	if linkSlug == "" {
+		linkSlug = id
 	}
 
-	return projects, data.LastPage, nil
+	return model.ScrapedProject{
+		Source:          "karlancer",
+		ExternalID:      id,
+		Title:           pickTitle(p.Title),
+		Link:            fmt.Sprintf("https://www.karlancer.com/projects/%s", linkSlug),
+		BudgetText:      common.FormatBudgetText(amountMin, amountMax),
+		AmountMin:       amountMin,
+		AmountMax:       amountMax,
+		Description:     p.Description,
+		ApprovedAt:      pickString(p.PublishedAt, p.ApprovedAt),
+		BiddingClosedAt: pickString(p.ExpiredAt, p.ExpiredAlt),
+		BidsCount:       pickIntPtr(p.BidsCount, p.BidsAlt),
+		Skills:          collectSkills(p.Skills),
+	}, ""
 }
 
 func pickID(values ...any) string {
@@ -0,0 +1,175 @@
+package ponisha
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/providers/common"
+)
+
+// Selectors for the server-rendered project list. They are intentionally loose so that
+// class-name churn does not break the fallback as easily as it breaks the primary strategy.
+const (
+	cardLinkSelector = `a[href*="/project/"]`
+	cardSelector     = `[data-testid="project-card"], .project-card, article`
+	titleSelector    = `h2, h3, [data-testid="project-title"]`
+	budgetSelector   = `[data-testid="project-budget"], .project-budget, .budget`
+	skillSelector    = `[data-testid="project-skill"], .project-skills a, .skills a, .skill`
+	pagerSelector    = `a[href*="page="]`
+)
+
+var (
+	projectPathPattern = regexp.MustCompile(`/project/([0-9A-Za-z]+)(?:/([^/?#]*))?`)
+	pageParamPattern   = regexp.MustCompile(`[?&]page=(\d+)`)
+)
+
+// extractFromHTML parses the rendered project cards. It reports false when the page has no
+// recognisable project cards at all.
+func extractFromHTML(doc *goquery.Document) (ponishaPage, bool) {
+	result := ponishaPage{diagnostics: model.ScrapeDiagnostics{Pages: 1}}
+
+	cards := findCards(doc)
+	if cards.Length() == 0 {
+		return result, false
+	}
+
+	seen := map[string]bool{}
+	result.projects = make([]model.ScrapedProject, 0, cards.Length())
+	cards.Each(func(_ int, card *goquery.Selection) {
+		project, reason := parseCard(card)
+		if reason == "" && seen[project.ExternalID] {
+			return
+		}
+		result.diagnostics.ItemsSeen++
+		if reason != "" {
+			result.diagnostics.Skip(reason)
+			return
+		}
+		seen[project.ExternalID] = true
+		result.diagnostics.ItemsParsed++
+		result.projects = append(result.projects, project)
+	})
+
+	result.totalPages = readPagerTotal(doc)
+	return result, true
+}
+
+// findCards prefers explicit card containers and otherwise treats the closest block
+// around each project link as the card.
+func findCards(doc *goquery.Document) *goquery.Selection {
+	cards := doc.Find(cardSelector).FilterFunction(func(_ int, s *goquery.Selection) bool {
+		return s.Find(cardLinkSelector).Length() > 0
+	})
+	if cards.Length() > 0 {
+		return cards
+	}
+
+	var nodes []*html.Node
+	doc.Find(cardLinkSelector).Each(func(_ int, link *goquery.Selection) {
+		card := link
+		for parent := link.Parent(); parent.Length() > 0 && !parent.Is("body"); parent = parent.Parent() {
+			card = parent
+			if hasCurrency(parent.Text()) {
+				break
+			}
+		}
+		nodes = append(nodes, card.Nodes...)
+	})
+	return doc.FindNodes(nodes...)
+}
+
+func parseCard(card *goquery.Selection) (model.ScrapedProject, model.SkipReason) {
+	link := card.Find(cardLinkSelector).First()
+	if goquery.NodeName(card) == "a" {
+		link = card
+	}
+	href, _ := link.Attr("href")
+	match := projectPathPattern.FindStringSubmatch(href)
+	if match == nil {
+		return model.ScrapedProject{}, model.SkipMissingID
+	}
+	id, slug := match[1], match[2]
+
+	budgetText := cleanText(card.Find(budgetSelector).First().Text())
+	if budgetText == "" {
+		budgetText = findBudgetLine(card)
+	}
+	amountMin, amountMax, ok := common.ParseBudgetText(budgetText)
+	if !ok && strings.IndexFunc(budgetText, unicode.IsDigit) < 0 {
+		// No amount at all, e.g. "توافقی".
+		return model.ScrapedProject{}, model.SkipNoBudget
+	}
+	if !ok {
+		return model.ScrapedProject{}, model.SkipUnparseableBudget
+	}
+
+	title := cleanText(card.Find(titleSelector).First().Text())
+	if title == "" {
+		title = cleanText(link.Text())
+	}
+
+	var skills []string
+	card.Find(skillSelector).Each(func(_ int, s *goquery.Selection) {
+		if name := cleanText(s.Text()); name != "" {
+			skills = append(skills, name)
+		}
+	})
+
+	return model.ScrapedProject{
+		Source:     "ponisha",
+		ExternalID: id,
+		Title:      title,
+		Link:       fmt.Sprintf("https://ponisha.ir/project/%s/%s", id, slug),
+		BudgetText: common.FormatBudgetText(amountMin, amountMax),
+		AmountMin:  amountMin,
+		AmountMax:  amountMax,
+		Skills:     skills,
+	}, ""
+}
+
+// findBudgetLine returns the first text node in the card that mentions a currency.
+func findBudgetLine(card *goquery.Selection) string {
+	var line string
+	card.Find("*").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		if s.Children().Length() > 0 {
+			return true
+		}
+		text := cleanText(s.Text())
+		if hasCurrency(text) {
+			line = text
+			return false
+		}
+		return true
+	})
+	return line
+}
+
+func hasCurrency(text string) bool {
+	return strings.Contains(text, "تومان") || strings.Contains(text, "ریال")
+}
+
+func readPagerTotal(doc *goquery.Document) int {
+	total := 0
+	doc.Find(pagerSelector).Each(func(_ int, s *goquery.Selection) {
+		href, _ := s.Attr("href")
+		match := pageParamPattern.FindStringSubmatch(href)
+		if match == nil {
+			return
+		}
+		if page, err := strconv.Atoi(match[1]); err == nil && page > total {
+			total = page
+		}
+	})
+	return total
+}
+
+func cleanText(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
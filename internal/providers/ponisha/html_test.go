@@ -0,0 +1,103 @@
+package ponisha
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"ponisha-go/internal/model"
+)
+
+func loadFixture(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestExtractFromHTMLReadsCardsAndPager(t *testing.T) {
+	page, ok := extractFromHTML(loadFixture(t, "projects.html"))
+	if !ok {
+		t.Fatal("no project cards found")
+	}
+
+	if len(page.projects) != 2 {
+		t.Fatalf("got %d projects, want 2: %+v", len(page.projects), page.projects)
+	}
+	first := page.projects[0]
+	if first.ExternalID != "a1b2c3" || first.Title != "طراحی API با Go" {
+		t.Errorf("first project = %q %q", first.ExternalID, first.Title)
+	}
+	if first.Link != "https://ponisha.ir/project/a1b2c3/go-api-service" {
+		t.Errorf("link = %q", first.Link)
+	}
+	if first.AmountMin != 1_500_000 || first.AmountMax != 3_000_000 {
+		t.Errorf("budget = %d-%d, want 1,500,000-3,000,000", first.AmountMin, first.AmountMax)
+	}
+	if strings.Join(first.Skills, ",") != "Go,PostgreSQL" {
+		t.Errorf("skills = %v", first.Skills)
+	}
+	if second := page.projects[1]; second.ExternalID != "d4e5f6" || second.AmountMin != 500_000 || second.AmountMax != 0 {
+		t.Errorf("second project = %+v", second)
+	}
+
+	diag := page.diagnostics
+	if diag.ItemsSeen != 4 || diag.ItemsParsed != 2 {
+		t.Errorf("seen %d, parsed %d; want 4 and 2 with the repeated card ignored", diag.ItemsSeen, diag.ItemsParsed)
+	}
+	if diag.Skipped[model.SkipNoBudget] != 1 || diag.Skipped[model.SkipMissingID] != 1 {
+		t.Errorf("skipped = %v, want one no_budget and one missing_id", diag.Skipped)
+	}
+	if page.totalPages != 7 {
+		t.Errorf("total pages = %d, want 7 from the pager", page.totalPages)
+	}
+}
+
+func TestExtractFromHTMLFallsBackToLinkBlocks(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body>
+		<div class="row"><div class="col">
+			<a href="/project/x1/vue-dashboard">داشبورد Vue</a>
+			<div><span>بودجه:</span> <span>۱۰ تا ۲۰ میلیون تومان</span></div>
+		</div></div>
+		<div class="row"><div class="col">
+			<a href="/project/x2">اپ موبایل</a>
+			<div><span>۲۰۰٬۰۰۰٬۰۰۰ ریال</span></div>
+		</div></div>
+	</body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	page, ok := extractFromHTML(doc)
+	if !ok || len(page.projects) != 2 {
+		t.Fatalf("ok = %v, projects = %+v; want both link blocks", ok, page.projects)
+	}
+	if p := page.projects[0]; p.ExternalID != "x1" || p.Title != "داشبورد Vue" || p.AmountMin != 10_000_000 || p.AmountMax != 20_000_000 {
+		t.Errorf("first project = %+v", p)
+	}
+	if p := page.projects[1]; p.ExternalID != "x2" || p.AmountMax != 20_000_000 {
+		t.Errorf("second project = %+v", p)
+	}
+	if page.totalPages != 0 {
+		t.Errorf("total pages = %d without a pager", page.totalPages)
+	}
+}
+
+func TestExtractFromHTMLWithoutCards(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body><p>در حال بارگذاری…</p></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := extractFromHTML(doc); ok {
+		t.Error("found project cards on a page without any")
+	}
+}
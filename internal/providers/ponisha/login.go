@@ -0,0 +1,23 @@
+package ponisha
+
+import (
+	"context"
+	"net/http"
+
+	"ponisha-go/internal/providers/session"
+)
+
+const DefaultLoginURL = "https://ponisha.ir/api/v1/auth/login"
+
+// Login returns the Ponisha login flow for loginURL, or DefaultLoginURL when it is empty.
+func Login(loginURL string) session.LoginFunc {
+	if loginURL == "" {
+		loginURL = DefaultLoginURL
+	}
+	return func(ctx context.Context, client *http.Client, creds session.Credentials) (string, error) {
+		return session.PostJSONLogin(ctx, client, loginURL, map[string]string{
+			"username": creds.Username,
+			"password": creds.Password,
+		})
+	}
+}
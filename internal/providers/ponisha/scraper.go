@@ -4,158 +4,249 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 
+	"ponisha-go/internal/logging"
 	"ponisha-go/internal/model"
 	"ponisha-go/internal/providers/common"
+	"ponisha-go/internal/tracing"
 )
 
 type PonishaScraper struct {
 	client *http.Client
+	base   string
+	gate   common.RequestGate
+	obs    common.ResponseObserver
+	ua     string
+}
+
+type Option func(*PonishaScraper)
+
+// WithBaseURL points the scraper at another search endpoint, e.g. a local stand-in.
+func WithBaseURL(base string) Option {
+	return func(p *PonishaScraper) {
+		p.base = base
+	}
+}
+
+// WithRobots makes every page request pass through gate first.
+func WithRobots(gate common.RequestGate) Option {
+	return func(p *PonishaScraper) {
+		p.gate = gate
+	}
+}
+
+// WithMetrics reports the status of every page request to obs.
+func WithMetrics(obs common.ResponseObserver) Option {
+	return func(p *PonishaScraper) {
+		p.obs = obs
+	}
+}
+
+// WithUserAgent replaces common.DefaultUserAgent on page requests.
+func WithUserAgent(userAgent string) Option {
+	return func(p *PonishaScraper) {
+		p.ua = userAgent
+	}
 }
 
 const (
 	ponishaBaseURL   = "https://ponisha.ir/search/projects"
-	ponishaUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36"
 	ponishaPageLimit = 4
 )
 
-func NewScraper(client *http.Client) *PonishaScraper {
-	return &PonishaScraper{client: client}
+func NewScraper(client *http.Client, options ...Option) *PonishaScraper {
+	p := &PonishaScraper{client: client, base: ponishaBaseURL, ua: common.DefaultUserAgent}
+	for _, option := range options {
+		option(p)
+	}
+	return p
 }
 
 func (p *PonishaScraper) Source() string {
 	return "ponisha"
 }
 
-func (p *PonishaScraper) Scrape(ctx context.Context) ([]model.ScrapedProject, error) {
-	firstPage, totalPages, err := p.fetchFirstPage(ctx)
+type ponishaPage struct {
+	projects    []model.ScrapedProject
+	totalPages  int
+	diagnostics model.ScrapeDiagnostics
+}
+
+func (p ponishaPage) scrapePage(number int) model.ScrapePage {
+	return model.ScrapePage{Source: "ponisha", Number: number, Projects: p.projects, Diagnostics: p.diagnostics}
+}
+
+func (p *PonishaScraper) Scrape(ctx context.Context) (model.ScrapeResult, error) {
+	return common.CollectPages(ctx, p.Stream)
+}
+
+// Stream sends every listing page on pages as soon as it is parsed. Sends block, so a slow
+// consumer throttles fetching. Only a failed first page aborts the stream; later failures are
+// sent as pages with Err set.
+func (p *PonishaScraper) Stream(ctx context.Context, pages chan<- model.ScrapePage) error {
+	ctx = logging.WithSource(ctx, "ponisha")
+	first, err := p.fetchFirstPage(ctx)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if err := common.SendPage(ctx, pages, first.scrapePage(1)); err != nil {
+		return err
 	}
-	if totalPages <= 1 {
-		return firstPage, nil
+	if first.totalPages <= 1 {
+		return nil
 	}
 
-	return p.fetchRemainingPages(ctx, totalPages, firstPage)
+	return p.streamRemainingPages(ctx, first.totalPages, pages)
 }
 
-func (p *PonishaScraper) fetchFirstPage(ctx context.Context) ([]model.ScrapedProject, int, error) {
+func (p *PonishaScraper) fetchFirstPage(ctx context.Context) (ponishaPage, error) {
 	page := 1
-	log.Printf("[ponisha] page %d/%d", page, page)
-	projects, totalPages, err := p.fetchPage(ctx, buildPonishaURL(page))
+	ctx = logging.WithPage(ctx, page)
+	slog.DebugContext(ctx, "fetching page")
+	result, err := p.fetchPage(ctx, buildPonishaURL(p.base, page))
 	if err != nil {
-		log.Printf("[ponisha] failed on page %d: %v", page, err)
-		return nil, 0, err
+		slog.WarnContext(ctx, "page failed", "error", err)
+		return ponishaPage{}, err
 	}
-	log.Printf("[ponisha] page %d found %d items (total pages: %d)", page, len(projects), totalPages)
-	return projects, totalPages, nil
+	slog.InfoContext(ctx, "page fetched", "items", len(result.projects), "total_pages", result.totalPages)
+	return result, nil
 }
 
-func (p *PonishaScraper) fetchRemainingPages(ctx context.Context, totalPages int, seed []model.ScrapedProject) ([]model.ScrapedProject, error) {
-	projects := make([]model.ScrapedProject, 0, len(seed)*totalPages)
-	projects = append(projects, seed...)
-
+func (p *PonishaScraper) streamRemainingPages(ctx context.Context, totalPages int, pages chan<- model.ScrapePage) error {
 	group, gctx := errgroup.WithContext(ctx)
 	group.SetLimit(ponishaPageLimit)
 
-	var mu sync.Mutex
 	for page := 2; page <= totalPages; page++ {
 		page := page
 		group.Go(func() error {
-			log.Printf("[ponisha] page %d/%d", page, totalPages)
-			pageProjects, _, err := p.fetchPage(gctx, buildPonishaURL(page))
+			pageCtx := logging.WithPage(gctx, page)
+			slog.DebugContext(pageCtx, "fetching page", "total_pages", totalPages)
+			pageResult, err := p.fetchPage(pageCtx, buildPonishaURL(p.base, page))
 			if err != nil {
-				log.Printf("[ponisha] failed on page %d: %v", page, err)
-				return nil
+				slog.WarnContext(pageCtx, "page failed", "error", err)
+				return common.SendPage(gctx, pages, model.ScrapePage{Source: "ponisha", Number: page, Err: err})
 			}
-			log.Printf("[ponisha] page %d found %d items (total pages: %d)", page, len(pageProjects), totalPages)
-			mu.Lock()
-			projects = append(projects, pageProjects...)
-			mu.Unlock()
-			return nil
+			slog.InfoContext(pageCtx, "page fetched", "items", len(pageResult.projects), "total_pages", totalPages)
+			return common.SendPage(gctx, pages, pageResult.scrapePage(page))
 		})
 	}
 
-	_ = group.Wait()
-	return projects, nil
+	return group.Wait()
 }
 
-func (p *PonishaScraper) fetchPage(ctx context.Context, url string) ([]model.ScrapedProject, int, error) {
+func (p *PonishaScraper) fetchPage(ctx context.Context, url string) (page ponishaPage, err error) {
+	ctx, span := tracing.Start(ctx, "ponisha fetchPage", trace.WithAttributes(attribute.String("url.full", url)))
+	defer func() {
+		span.SetAttributes(attribute.Int("items", len(page.projects)))
+		tracing.End(span, err)
+	}()
+
+	if p.gate != nil {
+		if err := p.gate.Acquire(ctx, url); err != nil {
+			return ponishaPage{}, err
+		}
+	}
+
 	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
 	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, 0, err
+		return ponishaPage{}, err
 	}
-	req.Header.Set("User-Agent", ponishaUserAgent)
+	req.Header.Set("User-Agent", p.ua)
 
 	resp, err := p.client.Do(req)
+	common.Observe(reqCtx, p.obs, "ponisha", resp, err)
 	if err != nil {
-		return nil, 0, err
+		return ponishaPage{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, 0, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		return ponishaPage{}, fmt.Errorf("unexpected status: %d", resp.StatusCode)
 	}
 
 	doc, err := goquery.NewDocumentFromReader(resp.Body)
 	if err != nil {
-		return nil, 0, err
+		return ponishaPage{}, err
 	}
 
-	return extractPonishaProjects(doc)
+	return extractPonishaProjects(ctx, doc)
 }
 
-func extractPonishaProjects(doc *goquery.Document) ([]model.ScrapedProject, int, error) {
-	var payload map[string]any
-	if err := decodeNextPayload(doc, &payload); err != nil {
-		return nil, 0, fmt.Errorf("json parse error: %w", err)
-	}
-	if payload == nil {
-		return nil, 0, nil
-	}
+const (
+	strategyNextData  = "next_data"
+	strategyHTMLCards = "html_cards"
+)
 
-	target := findProjectsQuery(payload)
-	if target == nil {
-		return nil, 0, nil
+// extractPonishaProjects reads the Next.js payload first and falls back to the rendered
+// project cards when the payload is missing or no longer has the expected shape.
+func extractPonishaProjects(ctx context.Context, doc *goquery.Document) (ponishaPage, error) {
+	result, found, err := extractFromNextData(doc)
+	if err == nil && found {
+		result.diagnostics.UseStrategy(strategyNextData)
+		return result, nil
 	}
 
-	data := nestedMap(target, "state", "data")
-	if data == nil {
-		return nil, 0, nil
+	fallback, fallbackFound := extractFromHTML(doc)
+	if fallbackFound {
+		if err != nil {
+			slog.WarnContext(ctx, "extraction strategy failed; falling back", "strategy", strategyNextData, "fallback", strategyHTMLCards, "error", err)
+		}
+		fallback.diagnostics.UseStrategy(strategyHTMLCards)
+		return fallback, nil
+	}
+	if err != nil {
+		return ponishaPage{}, err
 	}
 
-	totalPages := readTotalPages(data)
+	result.diagnostics.PagesWithoutPayload = 1
+	return result, nil
+}
+
+func extractFromNextData(doc *goquery.Document) (ponishaPage, bool, error) {
+	result := ponishaPage{diagnostics: model.ScrapeDiagnostics{Pages: 1}}
+
+	var payload map[string]any
+	if err := decodeNextPayload(doc, &payload); err != nil {
+		return ponishaPage{}, false, fmt.Errorf("json parse error: %w", err)
+	}
 
+	target := findProjectsQuery(payload)
+	data := nestedMap(target, "state", "data")
 	list, ok := data["data"].([]any)
 	if !ok {
-		return nil, totalPages, nil
+		return result, false, nil
 	}
 
-	projects := make([]model.ScrapedProject, 0, len(list))
+	result.totalPages = readTotalPages(data)
+	result.projects = make([]model.ScrapedProject, 0, len(list))
 	for _, item := range list {
-		project, ok := parseProject(item)
-		if !ok {
+		result.diagnostics.ItemsSeen++
+		project, reason := parseProject(item)
+		if reason != "" {
+			result.diagnostics.Skip(reason)
 			continue
 		}
-		projects = append(projects, project)
+		result.diagnostics.ItemsParsed++
+		result.projects = append(result.projects, project)
 	}
 
-	return projects, totalPages, nil
+	return result, true, nil
 }
 
-func buildPonishaURL(page int) string {
-	return fmt.Sprintf("%s?page=%d&order=approved_at%%7Cdesc&promotion=-&filterByProjectStatus=open", ponishaBaseURL, page)
+func buildPonishaURL(base string, page int) string {
+	return fmt.Sprintf("%s?page=%d&order=approved_at%%7Cdesc&promotion=-&filterByProjectStatus=open", base, page)
 }
 
 func decodeNextPayload(doc *goquery.Document, out *map[string]any) error {
@@ -189,22 +280,22 @@ func readTotalPages(data map[string]any) int {
 	return int(common.ToInt64(val))
 }
 
-func parseProject(item any) (model.ScrapedProject, bool) {
+func parseProject(item any) (model.ScrapedProject, model.SkipReason) {
 	p, ok := item.(map[string]any)
 	if !ok {
-		return model.ScrapedProject{}, false
+		return model.ScrapedProject{}, model.SkipMalformedItem
 	}
 
 	id := common.ToString(p["id"])
 	if id == "" {
-		return model.ScrapedProject{}, false
+		return model.ScrapedProject{}, model.SkipMissingID
 	}
 
 	slug := common.ToString(p["slug"])
 	amountMin := common.ToInt64(p["amount_min"])
 	amountMax := common.ToInt64(p["amount_max"])
-	if !model.IsAboveThreshold(amountMin, amountMax) {
-		return model.ScrapedProject{}, false
+	if amountMin <= 0 && amountMax <= 0 {
+		return model.ScrapedProject{}, model.SkipNoBudget
 	}
 
 	project := model.ScrapedProject{
@@ -226,7 +317,7 @@ func parseProject(item any) (model.ScrapedProject, bool) {
 	}
 
 	project.Skills = extractSkillNames(p["skills"])
-	return project, true
+	return project, ""
 }
 
 func extractSkillNames(raw any) []string {
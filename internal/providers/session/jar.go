@@ -0,0 +1,98 @@
+package session
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// jar is a cookie jar that remembers what it was given per origin so it can be persisted.
+type jar struct {
+	inner *cookiejar.Jar
+
+	mu       sync.Mutex
+	cookies  map[string][]*http.Cookie
+	onChange func()
+}
+
+func newJar(saved map[string][]*http.Cookie) (*jar, error) {
+	inner, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	j := &jar{inner: inner, cookies: map[string][]*http.Cookie{}}
+	now := time.Now()
+	for origin, cookies := range saved {
+		u, err := url.Parse(origin)
+		if err != nil {
+			continue
+		}
+		live := make([]*http.Cookie, 0, len(cookies))
+		for _, c := range cookies {
+			if c.Expires.IsZero() || c.Expires.After(now) {
+				live = append(live, c)
+			}
+		}
+		inner.SetCookies(u, live)
+		j.cookies[origin] = live
+	}
+	return j, nil
+}
+
+func (j *jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.inner.SetCookies(u, cookies)
+	if len(cookies) == 0 {
+		return
+	}
+
+	origin := u.Scheme + "://" + u.Host
+	j.mu.Lock()
+	merged := j.cookies[origin]
+	changed := false
+	for _, c := range cookies {
+		replaced := false
+		for i, existing := range merged {
+			if existing.Name == c.Name && existing.Path == c.Path && existing.Domain == c.Domain {
+				changed = changed || existing.Value != c.Value
+				merged[i] = c
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, c)
+			changed = true
+		}
+	}
+	j.cookies[origin] = merged
+	onChange := j.onChange
+	j.mu.Unlock()
+
+	// Servers often resend the same session cookie with every response; only a new or
+	// changed value is worth writing out.
+	if changed && onChange != nil {
+		onChange()
+	}
+}
+
+func (j *jar) Cookies(u *url.URL) []*http.Cookie {
+	return j.inner.Cookies(u)
+}
+
+func (j *jar) snapshot() map[string][]*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	out := make(map[string][]*http.Cookie, len(j.cookies))
+	for origin, cookies := range j.cookies {
+		out[origin] = append([]*http.Cookie(nil), cookies...)
+	}
+	return out
+}
+
+func (j *jar) empty() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.cookies) == 0
+}
@@ -0,0 +1,209 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+var ErrNoCredentials = errors.New("session has no credentials")
+
+type Credentials struct {
+	Username string
+	Password string
+}
+
+func (c Credentials) Empty() bool {
+	return c.Username == "" || c.Password == ""
+}
+
+// LoginFunc signs in with creds using client, whose cookie jar keeps any session cookies.
+// Providers that authenticate with a bearer token return it; cookie-based ones return "".
+type LoginFunc func(ctx context.Context, client *http.Client, creds Credentials) (string, error)
+
+// Session wraps a provider's HTTP client with a persistent cookie jar and logs in again
+// whenever the provider answers 401. A 403 is passed through: it usually means the account
+// may not see the page or a WAF is blocking, and logging in again would not help.
+type Session struct {
+	name  string
+	creds Credentials
+	login LoginFunc
+	store Store
+
+	jar         *jar
+	loginClient *http.Client
+	client      *http.Client
+
+	// mu serialises logins; saveMu guards token and store writes and may be taken while
+	// mu is held (the jar persists from inside a login), never the other way round.
+	mu         sync.Mutex
+	generation int
+
+	saveMu sync.Mutex
+	token  string
+}
+
+// New restores any saved state from store and returns a session built on base. The
+// base client's timeout and transport are reused for both login and page requests.
+func New(name string, base *http.Client, creds Credentials, login LoginFunc, store Store) (*Session, error) {
+	if creds.Empty() {
+		return nil, ErrNoCredentials
+	}
+
+	state, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load %s session: %w", name, err)
+	}
+
+	cookies, err := newJar(state.Cookies)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := base.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	s := &Session{
+		name:  name,
+		creds: creds,
+		login: login,
+		store: store,
+		jar:   cookies,
+		token: state.Token,
+	}
+	s.loginClient = &http.Client{Transport: transport, Jar: cookies, Timeout: base.Timeout}
+	s.client = &http.Client{
+		Transport:     &sessionTransport{session: s, base: transport},
+		Jar:           cookies,
+		Timeout:       base.Timeout,
+		CheckRedirect: base.CheckRedirect,
+	}
+	cookies.onChange = s.persist
+	return s, nil
+}
+
+// Client returns the authenticated client to hand to a provider's NewScraper.
+func (s *Session) Client() *http.Client {
+	return s.client
+}
+
+// Login signs in unconditionally and persists the resulting state.
+func (s *Session) Login(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loginLocked(ctx)
+}
+
+func (s *Session) loginLocked(ctx context.Context) error {
+	token, err := s.login(ctx, s.loginClient, s.creds)
+	if err != nil {
+		return fmt.Errorf("%s login: %w", s.name, err)
+	}
+	s.generation++
+	slog.InfoContext(ctx, "session logged in", "session", s.name)
+
+	s.saveMu.Lock()
+	s.token = token
+	s.saveMu.Unlock()
+	s.persist()
+	return nil
+}
+
+func (s *Session) currentToken() string {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	return s.token
+}
+
+// ensure logs in when nothing has been restored yet and returns the current generation.
+func (s *Session) ensure(ctx context.Context) (int, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation == 0 && s.currentToken() == "" && s.jar.empty() {
+		if err := s.loginLocked(ctx); err != nil {
+			return 0, "", err
+		}
+	}
+	return s.generation, s.currentToken(), nil
+}
+
+// relogin signs in again unless another request already did so after seen was observed.
+func (s *Session) relogin(ctx context.Context, seen int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != seen {
+		return s.currentToken(), nil
+	}
+	slog.InfoContext(ctx, "session rejected; logging in again", "session", s.name)
+	if err := s.loginLocked(ctx); err != nil {
+		return "", err
+	}
+	return s.currentToken(), nil
+}
+
+func (s *Session) persist() {
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+	if err := s.store.Save(State{Token: s.token, Cookies: s.jar.snapshot()}); err != nil {
+		slog.Error("session save failed", "session", s.name, "error", err)
+	}
+}
+
+type sessionTransport struct {
+	session *Session
+	base    http.RoundTripper
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	generation, token, err := t.session.ensure(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.base.RoundTrip(withToken(req, token))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	token, err = t.session.relogin(req.Context(), generation)
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	// Cookies from the first attempt were resolved before the re-login; take them fresh.
+	retry.Header.Del("Cookie")
+	for _, c := range t.session.jar.Cookies(retry.URL) {
+		retry.AddCookie(c)
+	}
+	return t.base.RoundTrip(withToken(retry, token))
+}
+
+func withToken(req *http.Request, token string) *http.Request {
+	if token == "" {
+		return req
+	}
+	clone := req.Clone(req.Context())
+	clone.Header.Set("Authorization", "Bearer "+token)
+	return clone
+}
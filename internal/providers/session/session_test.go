@@ -0,0 +1,199 @@
+package session
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingStore wraps a FileStore and counts saves.
+type countingStore struct {
+	*FileStore
+	mu    sync.Mutex
+	saves int
+}
+
+func (s *countingStore) Save(state State) error {
+	s.mu.Lock()
+	s.saves++
+	s.mu.Unlock()
+	return s.FileStore.Save(state)
+}
+
+func (s *countingStore) saveCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saves
+}
+
+// stand-in provider: /login issues a new "sid" cookie, /projects needs the latest one and
+// resends it unchanged on every response.
+type provider struct {
+	mu     sync.Mutex
+	logins int
+	sid    string
+}
+
+func (p *provider) loginCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.logins
+}
+
+func (p *provider) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		p.logins++
+		p.sid = "sid-" + strconv.Itoa(p.logins)
+		sid := p.sid
+		p.mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: sid, Path: "/"})
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /projects", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		sid := p.sid
+		p.mu.Unlock()
+		cookie, err := r.Cookie("sid")
+		if err != nil || cookie.Value != sid || sid == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: sid, Path: "/"})
+		w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+func TestSessionRelogsInAndPersistsCookies(t *testing.T) {
+	p := &provider{sid: "sid-live"}
+	srv := httptest.NewServer(p.handler())
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "provider.json")
+	// A stale cookie from an earlier run: the first request is rejected with 401.
+	stale := &http.Cookie{Name: "sid", Value: "sid-expired", Path: "/", Expires: time.Now().Add(time.Hour)}
+	if err := NewFileStore(path).Save(State{Cookies: map[string][]*http.Cookie{srv.URL: {stale}}}); err != nil {
+		t.Fatal(err)
+	}
+	store := &countingStore{FileStore: NewFileStore(path)}
+
+	login := func(ctx context.Context, client *http.Client, creds Credentials) (string, error) {
+		return PostJSONLogin(ctx, client, srv.URL+"/login", map[string]string{"username": creds.Username, "password": creds.Password})
+	}
+	s, err := New("provider", &http.Client{Timeout: 5 * time.Second}, Credentials{Username: "u", Password: "p"}, login, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	get := func() string {
+		t.Helper()
+		resp, err := s.Client().Get(srv.URL + "/projects")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+		return string(body)
+	}
+
+	if body := get(); body != "ok" {
+		t.Fatalf("body = %q", body)
+	}
+	if p.loginCount() != 1 {
+		t.Fatalf("logins = %d, want 1 after the 401", p.loginCount())
+	}
+
+	saved, err := NewFileStore(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cookies := saved.Cookies[srv.URL]; len(cookies) != 1 || cookies[0].Value != "sid-1" {
+		t.Fatalf("persisted cookies = %+v, want sid-1", cookies)
+	}
+
+	// The session cookie is resent unchanged: no more logins and no more writes.
+	saves := store.saveCount()
+	get()
+	get()
+	if p.loginCount() != 1 {
+		t.Fatalf("logins = %d, want 1", p.loginCount())
+	}
+	if got := store.saveCount(); got != saves {
+		t.Fatalf("saves = %d, want %d: unchanged cookies must not be persisted", got, saves)
+	}
+
+	// A restarted session picks up the persisted cookie without logging in.
+	restarted, err := New("provider", &http.Client{Timeout: 5 * time.Second}, Credentials{Username: "u", Password: "p"}, login, NewFileStore(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := restarted.Client().Get(srv.URL + "/projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || p.loginCount() != 1 {
+		t.Fatalf("after restart: status = %d, logins = %d; want 200 and 1", resp.StatusCode, p.loginCount())
+	}
+}
+
+func TestSessionDoesNotRelogInOnForbidden(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		logins   int
+		requests int
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		logins++
+		mu.Unlock()
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "sid-1", Path: "/"})
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /projects", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		w.WriteHeader(http.StatusForbidden)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	login := func(ctx context.Context, client *http.Client, creds Credentials) (string, error) {
+		return PostJSONLogin(ctx, client, srv.URL+"/login", map[string]string{"username": creds.Username, "password": creds.Password})
+	}
+	store := NewFileStore(filepath.Join(t.TempDir(), "provider.json"))
+	s, err := New("provider", &http.Client{Timeout: 5 * time.Second}, Credentials{Username: "u", Password: "p"}, login, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		resp, err := s.Client().Get(srv.URL + "/projects")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("status = %d, want 403 passed through", resp.StatusCode)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if logins != 1 || requests != 3 {
+		t.Fatalf("logins = %d, requests = %d; want the first-use login only and no retries", logins, requests)
+	}
+}
@@ -0,0 +1,61 @@
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// State is what a session persists between restarts.
+type State struct {
+	Token   string                    `json:"token,omitempty"`
+	Cookies map[string][]*http.Cookie `json:"cookies,omitempty"`
+}
+
+// Store persists session state. FileStore is the default; a database-backed store only
+// needs to implement these two methods.
+type Store interface {
+	Load() (State, error)
+	Save(state State) error
+}
+
+type FileStore struct {
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (f *FileStore) Load() (State, error) {
+	content, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, nil
+	}
+	if err != nil {
+		return State{}, err
+	}
+	var state State
+	if err := json.Unmarshal(content, &state); err != nil {
+		return State{}, err
+	}
+	return state, nil
+}
+
+// Save writes atomically so a crash never leaves a truncated session file behind.
+func (f *FileStore) Save(state State) error {
+	content, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return err
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path)
+}
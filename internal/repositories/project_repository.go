@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 
 	"ponisha-go/internal/model"
 )
@@ -11,4 +12,13 @@ var ErrNotFound = errors.New("record not found")
 
 type ProjectRepository interface {
 	CreateIfNotExists(ctx context.Context, input model.ProjectCreate) (model.Project, bool, error)
+	GetBySourceExternalID(ctx context.Context, source, externalID string) (model.Project, error)
+}
+
+// ProjectQueries reads saved projects for reporting, e.g. the Telegram bot commands.
+type ProjectQueries interface {
+	Latest(ctx context.Context, limit int) ([]model.Project, error)
+	// Search matches query anywhere in the title, ignoring case.
+	Search(ctx context.Context, query string, limit int) ([]model.Project, error)
+	Stats(ctx context.Context, since time.Time) ([]model.SourceStats, error)
 }
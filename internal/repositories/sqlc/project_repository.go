@@ -3,12 +3,15 @@ package sqlc
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 
 	db "ponisha-go/internal/db/sqlc"
 	"ponisha-go/internal/model"
+	"ponisha-go/internal/repositories"
 )
 
 type ProjectRepository struct {
@@ -38,6 +41,66 @@ func (r *ProjectRepository) CreateIfNotExists(ctx context.Context, input model.P
 	return mapProject(project), true, nil
 }
 
+func (r *ProjectRepository) GetBySourceExternalID(ctx context.Context, source, externalID string) (model.Project, error) {
+	project, err := r.queries.GetProjectBySourceExternalID(ctx, db.GetProjectBySourceExternalIDParams{
+		Source:     source,
+		ExternalID: externalID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Project{}, repositories.ErrNotFound
+	}
+	if err != nil {
+		return model.Project{}, err
+	}
+	return mapProject(project), nil
+}
+
+func (r *ProjectRepository) Latest(ctx context.Context, limit int) ([]model.Project, error) {
+	rows, err := r.queries.ListLatestProjects(ctx, int32(limit))
+	if err != nil {
+		return nil, err
+	}
+	return mapProjects(rows), nil
+}
+
+func (r *ProjectRepository) Search(ctx context.Context, query string, limit int) ([]model.Project, error) {
+	rows, err := r.queries.SearchProjects(ctx, db.SearchProjectsParams{
+		Query:    likeEscaper.Replace(query),
+		RowLimit: int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mapProjects(rows), nil
+}
+
+// likeEscaper makes LIKE wildcards in a search query match literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func (r *ProjectRepository) Stats(ctx context.Context, since time.Time) ([]model.SourceStats, error) {
+	rows, err := r.queries.ProjectStatsBySource(ctx, pgtype.Timestamptz{Time: since, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	stats := make([]model.SourceStats, 0, len(rows))
+	for _, row := range rows {
+		stat := model.SourceStats{Source: row.Source, Total: row.Total, Recent: row.Recent}
+		if row.LastCreatedAt.Valid {
+			stat.LastCreatedAt = row.LastCreatedAt.Time
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+func mapProjects(rows []db.Project) []model.Project {
+	projects := make([]model.Project, 0, len(rows))
+	for _, row := range rows {
+		projects = append(projects, mapProject(row))
+	}
+	return projects
+}
+
 func mapProject(project db.Project) model.Project {
 	var createdAt time.Time
 	if project.CreatedAt.Valid {
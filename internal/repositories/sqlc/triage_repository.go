@@ -0,0 +1,103 @@
+package sqlc
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	db "ponisha-go/internal/db/sqlc"
+	"ponisha-go/internal/model"
+)
+
+type TriageRepository struct {
+	queries *db.Queries
+}
+
+func NewTriageRepository(queries *db.Queries) *TriageRepository {
+	return &TriageRepository{queries: queries}
+}
+
+func (r *TriageRepository) Set(ctx context.Context, triage model.Triage) error {
+	return r.queries.UpsertTriage(ctx, db.UpsertTriageParams{
+		Source:     triage.Source,
+		ExternalID: triage.ExternalID,
+		UserID:     triage.UserID,
+		UserName:   triage.UserName,
+		State:      string(triage.State),
+		ChatID:     triage.ChatID,
+		MessageID:  triage.MessageID,
+		RemindAt:   timestamptz(triage.RemindAt),
+	})
+}
+
+func (r *TriageRepository) ListByProject(ctx context.Context, source, externalID string) ([]model.Triage, error) {
+	rows, err := r.queries.ListTriageByProject(ctx, db.ListTriageByProjectParams{
+		Source:     source,
+		ExternalID: externalID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Triage, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, mapTriage(row))
+	}
+	return out, nil
+}
+
+func (r *TriageRepository) ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]model.Triage, error) {
+	rows, err := r.queries.ClaimDueReminders(ctx, db.ClaimDueRemindersParams{
+		RemindAt: pgtype.Timestamptz{Time: now, Valid: true},
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	out := make([]model.Triage, 0, len(rows))
+	for _, row := range rows {
+		triage := mapTriage(db.ProjectTriage{
+			Source:     row.Source,
+			ExternalID: row.ExternalID,
+			UserID:     row.UserID,
+			UserName:   row.UserName,
+			State:      row.State,
+			ChatID:     row.ChatID,
+			MessageID:  row.MessageID,
+			RemindAt:   row.RemindAt,
+			RemindedAt: row.RemindedAt,
+			UpdatedAt:  row.UpdatedAt,
+		})
+		triage.Title = row.Title
+		triage.Link = row.Link
+		out = append(out, triage)
+	}
+	return out, nil
+}
+
+func mapTriage(row db.ProjectTriage) model.Triage {
+	triage := model.Triage{
+		Source:     row.Source,
+		ExternalID: row.ExternalID,
+		UserID:     row.UserID,
+		UserName:   row.UserName,
+		State:      model.TriageState(row.State),
+		ChatID:     row.ChatID,
+		MessageID:  row.MessageID,
+	}
+	if row.RemindAt.Valid {
+		remindAt := row.RemindAt.Time
+		triage.RemindAt = &remindAt
+	}
+	if row.UpdatedAt.Valid {
+		triage.UpdatedAt = row.UpdatedAt.Time
+	}
+	return triage
+}
+
+func timestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
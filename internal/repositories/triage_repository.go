@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+type TriageRepository interface {
+	// Set stores a user's state for a project, replacing the previous one.
+	Set(ctx context.Context, triage model.Triage) error
+	ListByProject(ctx context.Context, source, externalID string) ([]model.Triage, error)
+	// ClaimDueReminders marks up to limit reminders due at now as sent and returns them, so
+	// each reminder is handed to exactly one replica.
+	ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]model.Triage, error)
+}
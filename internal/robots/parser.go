@@ -0,0 +1,147 @@
+package robots
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+type rule struct {
+	pattern string
+	allow   bool
+}
+
+type ruleSet struct {
+	rules      []rule
+	crawlDelay time.Duration
+}
+
+// parse keeps the groups that name userAgent, or the "*" group when none do.
+func parse(content, userAgent string) *ruleSet {
+	token := strings.ToLower(productToken(userAgent))
+
+	var specific, wildcard ruleSet
+	var hasSpecific bool
+
+	var agents []string
+	inRules := false
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if inRules {
+				agents = nil
+				inRules = false
+			}
+			agents = append(agents, strings.ToLower(value))
+			continue
+		}
+		if len(agents) == 0 {
+			continue
+		}
+		inRules = true
+
+		for _, agent := range agents {
+			var target *ruleSet
+			switch {
+			case agent == token:
+				target = &specific
+				hasSpecific = true
+			case agent == "*":
+				target = &wildcard
+			default:
+				continue
+			}
+			target.apply(key, value)
+		}
+	}
+
+	if hasSpecific {
+		return &specific
+	}
+	return &wildcard
+}
+
+func (r *ruleSet) apply(key, value string) {
+	switch key {
+	case "allow":
+		if value != "" {
+			r.rules = append(r.rules, rule{pattern: value, allow: true})
+		}
+	case "disallow":
+		if value != "" {
+			r.rules = append(r.rules, rule{pattern: value, allow: false})
+		}
+	case "crawl-delay":
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+			r.crawlDelay = time.Duration(seconds * float64(time.Second))
+		}
+	}
+}
+
+// allowed applies the longest matching rule; on a tie Allow wins.
+func (r *ruleSet) allowed(path string) bool {
+	best := -1
+	allow := true
+	for _, rl := range r.rules {
+		if !matches(rl.pattern, path) {
+			continue
+		}
+		length := len(rl.pattern)
+		if length > best || (length == best && rl.allow) {
+			best = length
+			allow = rl.allow
+		}
+	}
+	return allow
+}
+
+// matches supports the "*" wildcard and the "$" end anchor; unanchored patterns match prefixes.
+func matches(pattern, path string) bool {
+	if strings.HasSuffix(pattern, "$") {
+		pattern = strings.TrimSuffix(pattern, "$")
+	} else {
+		pattern += "*"
+	}
+	return globMatch(pattern, path)
+}
+
+func globMatch(pattern, value string) bool {
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case p < len(pattern) && pattern[p] == value[v]:
+			p++
+			v++
+		case star >= 0:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// productToken returns the leading product name of a User-Agent header value.
+func productToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	token, _, _ = strings.Cut(token, " ")
+	return token
+}
@@ -0,0 +1,177 @@
+package robots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDisallowed = errors.New("disallowed by robots.txt")
+	// ErrUnavailable means robots.txt could not be fetched (network error or 5xx). Nothing on
+	// the host may be fetched until it is retried, and unlike ErrDisallowed it is a failure of
+	// the site, not a policy.
+	ErrUnavailable = errors.New("robots.txt unavailable")
+)
+
+const (
+	cacheTTL        = 24 * time.Hour
+	failureCacheTTL = 10 * time.Minute
+	maxRobotsSize   = 512 * 1024
+)
+
+// Checker fetches and caches robots.txt per host and enforces Crawl-delay between
+// requests to the same host. It is safe for concurrent use by several providers.
+type Checker struct {
+	client    *http.Client
+	userAgent string
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	mu        sync.Mutex
+	rules     *ruleSet
+	failure   error
+	expiresAt time.Time
+	next      time.Time
+}
+
+func NewChecker(client *http.Client, userAgent string) *Checker {
+	return &Checker{
+		client:    client,
+		userAgent: userAgent,
+		hosts:     map[string]*hostState{},
+	}
+}
+
+// Acquire returns ErrDisallowed if rawURL may not be fetched and ErrUnavailable if the host's
+// robots.txt could not be fetched, and otherwise blocks until the host's crawl delay has
+// elapsed since the previous request through this checker.
+func (c *Checker) Acquire(ctx context.Context, rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	host := c.host(target)
+	rules, err := c.rulesFor(ctx, host, target)
+	if err != nil {
+		return err
+	}
+
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	if !rules.allowed(path) {
+		return fmt.Errorf("%w: %s", ErrDisallowed, rawURL)
+	}
+
+	return host.wait(ctx, rules.crawlDelay)
+}
+
+func (c *Checker) host(target *url.URL) *hostState {
+	key := target.Scheme + "://" + target.Host
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st := c.hosts[key]
+	if st == nil {
+		st = &hostState{}
+		c.hosts[key] = st
+	}
+	return st
+}
+
+func (c *Checker) rulesFor(ctx context.Context, host *hostState, target *url.URL) (*ruleSet, error) {
+	host.mu.Lock()
+	defer host.mu.Unlock()
+
+	if (host.rules != nil || host.failure != nil) && time.Now().Before(host.expiresAt) {
+		return host.rules, host.failure
+	}
+
+	rules, err := c.fetch(ctx, target)
+	host.rules, host.failure = rules, err
+	if err != nil {
+		host.expiresAt = time.Now().Add(failureCacheTTL)
+		return nil, err
+	}
+	host.expiresAt = time.Now().Add(cacheTTL)
+	return rules, nil
+}
+
+// fetch follows RFC 9309: a missing robots.txt allows everything, while an unreachable or
+// failing one blocks the host, reported as ErrUnavailable, until it is retried.
+func (c *Checker) fetch(ctx context.Context, target *url.URL) (*ruleSet, error) {
+	robotsURL := target.Scheme + "://" + target.Host + "/robots.txt"
+
+	reqCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		slog.WarnContext(ctx, "robots.txt fetch failed", "url", robotsURL, "error", err)
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsSize))
+		if err != nil {
+			slog.WarnContext(ctx, "robots.txt read failed", "url", robotsURL, "error", err)
+			return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+		}
+		return parse(string(body), c.userAgent), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &ruleSet{}, nil
+	default:
+		slog.WarnContext(ctx, "robots.txt fetch returned an error status", "url", robotsURL, "status", resp.StatusCode)
+		return nil, fmt.Errorf("%w: %s returned status %d", ErrUnavailable, robotsURL, resp.StatusCode)
+	}
+}
+
+func (h *hostState) wait(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	h.mu.Lock()
+	start := time.Now()
+	if h.next.After(start) {
+		start = h.next
+	}
+	h.next = start.Add(delay)
+	h.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -2,41 +2,160 @@ package scheduler
 
 import (
 	"context"
-	"log"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/robfig/cron/v3"
 
 	"ponisha-go/internal/services/scraping"
 )
 
+// Schedule is a cron spec, including "@every 5m" intervals, plus a random start delay of
+// up to Jitter. An empty Spec falls back to the scheduler's default spec.
+type Schedule struct {
+	Spec   string
+	Jitter time.Duration
+}
+
 type Scheduler struct {
-	cron    *cron.Cron
-	service *scraping.Service
-	spec    string
+	cron      *cron.Cron
+	service   *scraping.Service
+	spec      string
+	jitter    time.Duration
+	overrides map[string]Schedule
+	paused    atomic.Bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type Option func(*Scheduler)
+
+// WithJitter delays every run of the default schedule by a random amount up to jitter.
+func WithJitter(jitter time.Duration) Option {
+	return func(s *Scheduler) {
+		s.jitter = jitter
+	}
+}
+
+// WithSourceSchedule gives one provider its own schedule instead of the default spec.
+func WithSourceSchedule(source string, schedule Schedule) Option {
+	return func(s *Scheduler) {
+		s.overrides[source] = schedule
+	}
 }
 
-func New(spec string, service *scraping.Service) *Scheduler {
-	return &Scheduler{
-		cron:    cron.New(),
-		service: service,
-		spec:    spec,
+func New(spec string, service *scraping.Service, options ...Option) *Scheduler {
+	s := &Scheduler{
+		cron:      cron.New(),
+		service:   service,
+		spec:      spec,
+		overrides: map[string]Schedule{},
+		stop:      make(chan struct{}),
 	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+type job struct {
+	schedule Schedule
+	sources  []string
+}
+
+// jobs groups providers that share a schedule so they are scraped in one run.
+func (s *Scheduler) jobs() ([]job, error) {
+	known := map[string]bool{}
+	for _, source := range s.service.Sources() {
+		known[source] = true
+	}
+	for source := range s.overrides {
+		if !known[source] {
+			return nil, fmt.Errorf("schedule for unknown source %q", source)
+		}
+	}
+
+	var jobs []job
+	index := map[Schedule]int{}
+	for _, source := range s.service.Sources() {
+		schedule := Schedule{Spec: s.spec, Jitter: s.jitter}
+		if override, ok := s.overrides[source]; ok {
+			if override.Spec != "" {
+				schedule.Spec = override.Spec
+			}
+			schedule.Jitter = override.Jitter
+		}
+		i, ok := index[schedule]
+		if !ok {
+			i = len(jobs)
+			index[schedule] = i
+			jobs = append(jobs, job{schedule: schedule})
+		}
+		jobs[i].sources = append(jobs[i].sources, source)
+	}
+	return jobs, nil
 }
 
 func (s *Scheduler) Start() error {
-	_, err := s.cron.AddFunc(s.spec, func() {
-		log.Printf("scheduled scrape triggered")
-		go s.service.Run(context.Background())
-	})
+	jobs, err := s.jobs()
 	if err != nil {
 		return err
 	}
 
+	for _, j := range jobs {
+		j := j
+		_, err := s.cron.AddFunc(j.schedule.Spec, func() {
+			slog.Info("scheduled scrape triggered", "sources", strings.Join(j.sources, ","))
+			go s.run(j)
+		})
+		if err != nil {
+			return fmt.Errorf("schedule %s: %w", strings.Join(j.sources, ","), err)
+		}
+		slog.Info("scrape scheduled", "sources", strings.Join(j.sources, ","), "spec", j.schedule.Spec, "jitter", j.schedule.Jitter)
+	}
+
 	s.cron.Start()
 	return nil
 }
 
+// Pause skips scheduled runs until Resume; manual runs are not affected.
+func (s *Scheduler) Pause() {
+	s.paused.Store(true)
+}
+
+func (s *Scheduler) Resume() {
+	s.paused.Store(false)
+}
+
+func (s *Scheduler) Paused() bool {
+	return s.paused.Load()
+}
+
+func (s *Scheduler) run(j job) {
+	if s.paused.Load() {
+		slog.Info("scheduled scrape skipped; scheduler paused", "sources", strings.Join(j.sources, ","))
+		return
+	}
+	if j.schedule.Jitter > 0 {
+		timer := time.NewTimer(rand.N(j.schedule.Jitter))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-s.stop:
+			return
+		}
+	}
+	s.service.Run(context.Background(), j.sources...)
+}
+
 func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() { close(s.stop) })
 	ctx := s.cron.Stop()
 	<-ctx.Done()
 }
@@ -0,0 +1,81 @@
+package scraping
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"ponisha-go/internal/notifiers"
+)
+
+// DeliveryStats counts one provider's project alerts since the service started. Pending
+// alerts are queued but not yet sent.
+type DeliveryStats struct {
+	Source       string     `json:"source"`
+	Pending      int        `json:"pending"`
+	Delivered    int        `json:"delivered"`
+	Failed       int        `json:"failed"`
+	LastError    string     `json:"lastError,omitempty"`
+	LastFailedAt *time.Time `json:"lastFailedAt,omitempty"`
+}
+
+type deliveryTracker struct {
+	mu    sync.Mutex
+	stats map[string]*DeliveryStats
+}
+
+func newDeliveryTracker() *deliveryTracker {
+	return &deliveryTracker{stats: map[string]*DeliveryStats{}}
+}
+
+// watch counts delivery as pending until it completes. ctx only provides log attributes.
+func (t *deliveryTracker) watch(ctx context.Context, source string, delivery *notifiers.Delivery) {
+	t.mu.Lock()
+	t.get(source).Pending++
+	t.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		<-delivery.Done()
+		err := delivery.Err()
+
+		t.mu.Lock()
+		stats := t.get(source)
+		stats.Pending--
+		if err != nil {
+			now := time.Now()
+			stats.Failed++
+			stats.LastError = err.Error()
+			stats.LastFailedAt = &now
+		} else {
+			stats.Delivered++
+		}
+		t.mu.Unlock()
+
+		if err != nil {
+			slog.ErrorContext(ctx, "alert delivery failed", "error", err)
+		}
+	}()
+}
+
+func (t *deliveryTracker) get(source string) *DeliveryStats {
+	stats := t.stats[source]
+	if stats == nil {
+		stats = &DeliveryStats{Source: source}
+		t.stats[source] = stats
+	}
+	return stats
+}
+
+func (t *deliveryTracker) snapshot() []DeliveryStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]DeliveryStats, 0, len(t.stats))
+	for _, stats := range t.stats {
+		out = append(out, *stats)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
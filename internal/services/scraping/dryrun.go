@@ -0,0 +1,137 @@
+package scraping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+	"ponisha-go/internal/providers/common"
+	"ponisha-go/internal/repositories"
+)
+
+// DryRun runs the full pipeline against a read-only view of the repository and records the
+// alerts it would send instead of sending them. It takes no locks, leaves provider health
+// untouched and does not block or count as a regular run.
+func (s *Service) DryRun(ctx context.Context, sources ...string) (RunReport, error) {
+	selected, err := s.selectScrapers(sources)
+	if err != nil {
+		return RunReport{}, err
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return RunReport{}, ErrShuttingDown
+	}
+	s.inflight.Add(1)
+	s.mu.Unlock()
+	defer s.inflight.Done()
+
+	runCtx, cancel := s.runContext(common.Unobserved(ctx))
+	defer cancel()
+
+	recorder := &recordingNotifier{renderer: s.renderer()}
+	env := runEnv{runID: newRunID(), repo: newDryRunRepository(s.repo), notifier: recorder, dryRun: true}
+	runCtx, span := startRun(runCtx, env)
+	report := s.scrape(runCtx, env, selected, map[string]string{})
+	endRun(span, report)
+	report.DryRun = true
+	report.Alerts = recorder.alerts
+	return report, nil
+}
+
+func (s *Service) renderer() AlertRenderer {
+	if renderer, ok := s.notifier.(AlertRenderer); ok {
+		return renderer
+	}
+	return plainRenderer{}
+}
+
+// runEnv is what a run writes to: the real repository and notifier, or dry-run stand-ins.
+// deliveries is nil for dry runs.
+type runEnv struct {
+	runID         string
+	repo          repositories.ProjectRepository
+	notifier      Notifier
+	notifyTimeout time.Duration
+	deliveries    *deliveryTracker
+	dryRun        bool
+}
+
+// dryRunRepository answers CreateIfNotExists from reads only, and remembers what it
+// would have created so repeated listings in one run still count as duplicates.
+type dryRunRepository struct {
+	repo repositories.ProjectRepository
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func newDryRunRepository(repo repositories.ProjectRepository) *dryRunRepository {
+	return &dryRunRepository{repo: repo, seen: map[string]bool{}}
+}
+
+func (r *dryRunRepository) CreateIfNotExists(ctx context.Context, input model.ProjectCreate) (model.Project, bool, error) {
+	key := input.Source + "\x00" + input.ExternalID
+	r.mu.Lock()
+	if r.seen[key] {
+		r.mu.Unlock()
+		return model.Project{}, false, nil
+	}
+	r.seen[key] = true
+	r.mu.Unlock()
+
+	existing, err := r.repo.GetBySourceExternalID(ctx, input.Source, input.ExternalID)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, repositories.ErrNotFound) {
+		return model.Project{}, false, err
+	}
+	return model.Project{
+		Source:     input.Source,
+		ExternalID: input.ExternalID,
+		Title:      input.Title,
+		Link:       input.Link,
+		BudgetText: input.BudgetText,
+		AmountMin:  input.AmountMin,
+		AmountMax:  input.AmountMax,
+	}, true, nil
+}
+
+func (r *dryRunRepository) GetBySourceExternalID(ctx context.Context, source, externalID string) (model.Project, error) {
+	return r.repo.GetBySourceExternalID(ctx, source, externalID)
+}
+
+type recordingNotifier struct {
+	renderer AlertRenderer
+
+	mu     sync.Mutex
+	alerts []string
+}
+
+func (n *recordingNotifier) SendAlert(_ context.Context, project model.ScrapedProject) (*notifiers.Delivery, error) {
+	n.record(n.renderer.RenderAlert(project))
+	return notifiers.Delivered(nil), nil
+}
+
+func (n *recordingNotifier) SendSystemAlert(_ context.Context, alert model.SystemAlert) (*notifiers.Delivery, error) {
+	n.record(fmt.Sprintf("[%s] %s: %s", alert.Kind, alert.Source, alert.Message))
+	return notifiers.Delivered(nil), nil
+}
+
+func (n *recordingNotifier) record(text string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, text)
+}
+
+type plainRenderer struct{}
+
+func (plainRenderer) RenderAlert(project model.ScrapedProject) string {
+	return fmt.Sprintf("%s\n%s\n%s", project.Title, project.BudgetText, project.Link)
+}
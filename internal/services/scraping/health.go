@@ -0,0 +1,126 @@
+package scraping
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	ProviderHealthy = "healthy"
+	ProviderFailing = "failing"
+	ProviderDown    = "down"
+)
+
+// HealthPolicy controls when a provider is considered down and how long it is skipped.
+type HealthPolicy struct {
+	// DownAfter is the number of consecutive failures before the provider is reported down.
+	DownAfter int
+	// BaseBackoff is the delay after the first failure; it doubles with each further failure.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+func DefaultHealthPolicy() HealthPolicy {
+	return HealthPolicy{
+		DownAfter:   3,
+		BaseBackoff: 5 * time.Minute,
+		MaxBackoff:  2 * time.Hour,
+	}
+}
+
+type ProviderHealth struct {
+	Source              string    `json:"source"`
+	Status              string    `json:"status"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastError           string    `json:"lastError,omitempty"`
+	LastSuccessAt       time.Time `json:"lastSuccessAt"`
+	LastFailureAt       time.Time `json:"lastFailureAt"`
+	NextAttemptAt       time.Time `json:"nextAttemptAt"`
+}
+
+type healthTracker struct {
+	mu     sync.Mutex
+	policy HealthPolicy
+	states map[string]*ProviderHealth
+}
+
+func newHealthTracker(policy HealthPolicy) *healthTracker {
+	return &healthTracker{policy: policy, states: map[string]*ProviderHealth{}}
+}
+
+func (h *healthTracker) state(source string) *ProviderHealth {
+	st := h.states[source]
+	if st == nil {
+		st = &ProviderHealth{Source: source, Status: ProviderHealthy}
+		h.states[source] = st
+	}
+	return st
+}
+
+// ready reports whether source is outside its backoff window.
+func (h *healthTracker) ready(source string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return !now.Before(h.state(source).NextAttemptAt)
+}
+
+// recordSuccess resets the failure streak and reports whether the provider was down before.
+func (h *healthTracker) recordSuccess(source string, now time.Time) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	st := h.state(source)
+	wasDown := st.Status == ProviderDown
+	st.Status = ProviderHealthy
+	st.ConsecutiveFailures = 0
+	st.LastError = ""
+	st.LastSuccessAt = now
+	st.NextAttemptAt = time.Time{}
+	return wasDown
+}
+
+// recordFailure extends the backoff window and reports whether this failure took the
+// provider down, so the caller notifies exactly once per outage.
+func (h *healthTracker) recordFailure(source string, err error, now time.Time) (ProviderHealth, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	st := h.state(source)
+	st.ConsecutiveFailures++
+	st.LastError = err.Error()
+	st.LastFailureAt = now
+	st.NextAttemptAt = now.Add(h.backoff(st.ConsecutiveFailures))
+
+	wentDown := false
+	if st.ConsecutiveFailures >= h.policy.DownAfter {
+		wentDown = st.Status != ProviderDown
+		st.Status = ProviderDown
+	} else {
+		st.Status = ProviderFailing
+	}
+	return *st, wentDown
+}
+
+func (h *healthTracker) backoff(failures int) time.Duration {
+	delay := h.policy.BaseBackoff
+	for i := 1; i < failures && delay < h.policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if h.policy.MaxBackoff > 0 && delay > h.policy.MaxBackoff {
+		delay = h.policy.MaxBackoff
+	}
+	return delay
+}
+
+func (h *healthTracker) snapshot() []ProviderHealth {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	out := make([]ProviderHealth, 0, len(h.states))
+	for _, st := range h.states {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
@@ -0,0 +1,116 @@
+package scraping
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+const (
+	// LockPerSource lets replicas split providers between them.
+	LockPerSource = "source"
+	// LockPerRun allows a single replica to scrape at a time.
+	LockPerRun = "run"
+)
+
+// LockState is what this replica last observed about a coordination lock.
+type LockState struct {
+	Name       string    `json:"name"`
+	Owner      string    `json:"owner"`
+	Held       bool      `json:"held"`
+	ObservedAt time.Time `json:"observedAt"`
+}
+
+// WithLocker coordinates runs across replicas through locker. scope is LockPerSource or
+// LockPerRun, and replicaID names this process in logs and the status API.
+func WithLocker(locker Locker, scope, replicaID string) Option {
+	return func(s *Service) {
+		s.locker = locker
+		s.lockScope = scope
+		s.replicaID = replicaID
+	}
+}
+
+// acquireLocks returns the scrapers this replica may run, recording the others in skipped,
+// and a function that releases every lock taken.
+func (s *Service) acquireLocks(ctx context.Context, scrapers []SiteScraper, skipped map[string]string) ([]SiteScraper, func()) {
+	if s.locker == nil {
+		return scrapers, func() {}
+	}
+
+	var releases []func()
+	releaseAll := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	if s.lockScope == LockPerRun {
+		release, reason := s.tryLock(ctx, "scrape")
+		if release == nil {
+			for _, sc := range scrapers {
+				skipped[sc.Source()] = reason
+			}
+			return nil, releaseAll
+		}
+		releases = append(releases, release)
+		return scrapers, releaseAll
+	}
+
+	locked := make([]SiteScraper, 0, len(scrapers))
+	for _, sc := range scrapers {
+		release, reason := s.tryLock(ctx, "scrape:"+sc.Source())
+		if release == nil {
+			skipped[sc.Source()] = reason
+			continue
+		}
+		releases = append(releases, release)
+		locked = append(locked, sc)
+	}
+	return locked, releaseAll
+}
+
+func (s *Service) tryLock(ctx context.Context, name string) (func(), string) {
+	release, holder, err := s.locker.TryLock(ctx, name)
+	if err != nil {
+		slog.ErrorContext(ctx, "lock error", "lock", name, "error", err)
+		return nil, "lock error: " + err.Error()
+	}
+	if release == nil {
+		if holder == "" {
+			holder = "another replica"
+		}
+		slog.InfoContext(ctx, "lock held elsewhere; skipping", "lock", name, "holder", holder)
+		s.setLock(LockState{Name: name, Owner: holder, ObservedAt: time.Now()})
+		return nil, "locked by " + holder
+	}
+
+	slog.InfoContext(ctx, "lock acquired", "lock", name, "replica", s.replicaID)
+	s.setLock(LockState{Name: name, Owner: s.replicaID, Held: true, ObservedAt: time.Now()})
+	return func() {
+		release()
+		slog.InfoContext(ctx, "lock released", "lock", name, "replica", s.replicaID)
+		s.mu.Lock()
+		delete(s.locks, name)
+		s.mu.Unlock()
+	}, ""
+}
+
+func (s *Service) setLock(state LockState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locks[state.Name] = state
+}
+
+func (s *Service) lockSnapshot() []LockState {
+	s.mu.Lock()
+	out := make([]LockState, 0, len(s.locks))
+	for _, state := range s.locks {
+		out = append(out, state)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,221 @@
+package scraping
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+// Stage names one step of the per-project pipeline. Every scraped project goes through
+// fetch, normalize, filter, persist and notify in that order; a project dropped by one stage
+// skips the rest.
+type Stage string
+
+const (
+	// StageFetch sees each project exactly as the provider parsed it.
+	StageFetch Stage = "fetch"
+	// StageNormalize tidies fields before anything else looks at them.
+	StageNormalize Stage = "normalize"
+	// StageFilter drops projects below the budget threshold.
+	StageFilter Stage = "filter"
+	// StagePersist saves new projects and drops ones that are already stored.
+	StagePersist Stage = "persist"
+	// StageNotify sends the alert for a newly saved project.
+	StageNotify Stage = "notify"
+)
+
+var stages = []Stage{StageFetch, StageNormalize, StageFilter, StagePersist, StageNotify}
+
+// Item is a project moving through the pipeline. Stages and interceptors may modify Project
+// in place. DryRun is set when nothing should be written or sent, so interceptors with side
+// effects can skip them.
+type Item struct {
+	Project model.ScrapedProject
+	RunID   string
+	DryRun  bool
+}
+
+// Annotate attaches metadata to the project; it travels with it into the alert and the run report.
+func (i *Item) Annotate(key, value string) {
+	if i.Project.Annotations == nil {
+		i.Project.Annotations = map[string]string{}
+	}
+	i.Project.Annotations[key] = value
+}
+
+// StageHandler runs one stage for an item. An error made by Drop removes the item quietly;
+// any other error is logged and removes it as well.
+type StageHandler func(ctx context.Context, item *Item) error
+
+// Interceptor wraps a stage. It can change the item before calling next, look at the result
+// afterwards, or return Drop without calling next to remove the item.
+type Interceptor func(ctx context.Context, item *Item, next StageHandler) error
+
+// WithInterceptor adds interceptor around stage. Interceptors added first run outermost.
+func WithInterceptor(stage Stage, interceptor Interceptor) Option {
+	return func(s *Service) {
+		s.interceptors[stage] = append(s.interceptors[stage], interceptor)
+	}
+}
+
+// Drop returns the error a stage or interceptor uses to take an item out of the pipeline.
+// The reason shows up in the source report's dropped counters.
+func Drop(reason string) error {
+	return &dropError{reason: reason}
+}
+
+type dropError struct {
+	reason string
+}
+
+func (e *dropError) Error() string {
+	return "dropped: " + e.reason
+}
+
+const (
+	dropBelowThreshold = "below threshold"
+	dropDuplicate      = "duplicate"
+)
+
+type pipeline struct {
+	handlers map[Stage]StageHandler
+}
+
+// newPipeline wires the built-in stages for one run to env and wraps them in the
+// configured interceptors.
+func (s *Service) newPipeline(env runEnv) pipeline {
+	base := map[Stage]StageHandler{
+		StageFetch:     func(context.Context, *Item) error { return nil },
+		StageNormalize: normalizeStage,
+		StageFilter:    filterStage(s.Threshold()),
+		StagePersist:   env.persistStage,
+		StageNotify:    env.notifyStage,
+	}
+
+	handlers := make(map[Stage]StageHandler, len(base))
+	for stage, handler := range base {
+		chain := s.interceptors[stage]
+		for i := len(chain) - 1; i >= 0; i-- {
+			handler = intercept(chain[i], handler)
+		}
+		handlers[stage] = handler
+	}
+	return pipeline{handlers: handlers}
+}
+
+func intercept(interceptor Interceptor, next StageHandler) StageHandler {
+	return func(ctx context.Context, item *Item) error {
+		return interceptor(ctx, item, next)
+	}
+}
+
+// process runs item through every stage and updates src's counters. It reports whether the
+// item made it through notify.
+func (p pipeline) process(ctx context.Context, item *Item, src *SourceReport) bool {
+	for _, stage := range stages {
+		err := p.handlers[stage](ctx, item)
+		if err == nil {
+			switch stage {
+			case StageFilter:
+				src.OverThreshold++
+			case StagePersist:
+				src.Saved++
+			case StageNotify:
+				src.AlertsQueued++
+			}
+			continue
+		}
+
+		var drop *dropError
+		if !errors.As(err, &drop) {
+			slog.WarnContext(ctx, "pipeline stage failed", "stage", stage, "external_id", item.Project.ExternalID, "error", err)
+			if stage == StageNotify {
+				src.AlertsFailed++
+			}
+			return false
+		}
+		switch {
+		case stage == StageFilter && drop.reason == dropBelowThreshold:
+			src.BelowThreshold++
+		case stage == StagePersist && drop.reason == dropDuplicate:
+			src.Duplicates++
+		default:
+			if src.Dropped == nil {
+				src.Dropped = map[string]int{}
+			}
+			src.Dropped[string(stage)+": "+drop.reason]++
+		}
+		return false
+	}
+	return true
+}
+
+func normalizeStage(_ context.Context, item *Item) error {
+	item.Project.Title = strings.TrimSpace(item.Project.Title)
+	item.Project.Link = strings.TrimSpace(item.Project.Link)
+	return nil
+}
+
+// filterStage drops projects at or below threshold; the threshold is fixed for the whole run.
+func filterStage(threshold int64) StageHandler {
+	return func(_ context.Context, item *Item) error {
+		if !model.IsAboveThreshold(item.Project.AmountMin, item.Project.AmountMax, threshold) {
+			return Drop(dropBelowThreshold)
+		}
+		return nil
+	}
+}
+
+func (env runEnv) persistStage(ctx context.Context, item *Item) error {
+	project := item.Project
+	saved, created, err := env.repo.CreateIfNotExists(ctx, model.ProjectCreate{
+		Source:     project.Source,
+		ExternalID: project.ExternalID,
+		Title:      project.Title,
+		Link:       project.Link,
+		BudgetText: project.BudgetText,
+		AmountMin:  project.AmountMin,
+		AmountMax:  project.AmountMax,
+	})
+	if err != nil {
+		return err
+	}
+	if !created {
+		if project.Source == "karlancer" {
+			slog.InfoContext(ctx, "duplicate high-budget project",
+				"external_id", project.ExternalID, "title", project.Title, "amount_min", project.AmountMin,
+				"amount_max", project.AmountMax, "link", project.Link,
+			)
+		}
+		return Drop(dropDuplicate)
+	}
+	item.Project.Source = saved.Source
+	item.Project.Link = saved.Link
+	return nil
+}
+
+// notifyStage queues the alert. When the notifier's queue is full it waits up to the notify
+// timeout, slowing the run down rather than piling up alerts, and then fails the item.
+func (env runEnv) notifyStage(ctx context.Context, item *Item) error {
+	enqueueCtx, cancel := withTimeout(ctx, env.notifyTimeout)
+	defer cancel()
+	delivery, err := env.notifier.SendAlert(enqueueCtx, item.Project)
+	if err != nil {
+		return err
+	}
+	if env.deliveries != nil {
+		env.deliveries.watch(ctx, item.Project.Source, delivery)
+	}
+	return nil
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -2,15 +2,57 @@ package scraping
 
 import (
 	"context"
+	"time"
 
 	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
 )
 
 type SiteScraper interface {
 	Source() string
-	Scrape(ctx context.Context) ([]model.ScrapedProject, error)
+	Scrape(ctx context.Context) (model.ScrapeResult, error)
 }
 
+// StreamingScraper emits pages as they are fetched so the service can persist and notify
+// without waiting for every provider to finish. Sends on pages block, which provides backpressure.
+type StreamingScraper interface {
+	SiteScraper
+	Stream(ctx context.Context, pages chan<- model.ScrapePage) error
+}
+
+// Notifier queues alerts for delivery. A call returns once the alert is queued, waiting for
+// room until ctx is done, and the returned Delivery completes when the alert has actually
+// been sent. An error means the alert was not queued at all (notifiers.ErrQueueFull,
+// notifiers.ErrClosed, ...). ctx also carries the run's log and trace context.
 type Notifier interface {
-	SendAlert(project model.ScrapedProject)
+	SendAlert(ctx context.Context, project model.ScrapedProject) (*notifiers.Delivery, error)
+	SendSystemAlert(ctx context.Context, alert model.SystemAlert) (*notifiers.Delivery, error)
+}
+
+// AlertRenderer is implemented by notifiers that can show the text an alert would have,
+// which dry runs return instead of sending.
+type AlertRenderer interface {
+	RenderAlert(project model.ScrapedProject) string
+}
+
+// Metrics receives run, provider and project counters. Dry runs are not reported, and they
+// mark their context with common.Unobserved so provider HTTP responses are not either.
+type Metrics interface {
+	ObserveRun(err error)
+	ObserveSource(source string, duration time.Duration, skipped bool, err error)
+	ObservePage(source string, err error)
+	ObserveProjects(source, outcome string, n int)
+}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveRun(error)                                 {}
+func (noopMetrics) ObserveSource(string, time.Duration, bool, error) {}
+func (noopMetrics) ObservePage(string, error)                        {}
+func (noopMetrics) ObserveProjects(string, string, int)              {}
+
+// Locker coordinates runs between replicas. TryLock must not wait: when another replica
+// holds name it returns a nil release and, if known, the holder's name.
+type Locker interface {
+	TryLock(ctx context.Context, name string) (release func(), holder string, err error)
 }
@@ -0,0 +1,66 @@
+package scraping
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+// RunReport summarises one scrape run across all providers.
+type RunReport struct {
+	RunID      string                 `json:"runId"`
+	DryRun     bool                   `json:"dryRun,omitempty"`
+	StartedAt  time.Time              `json:"startedAt"`
+	FinishedAt time.Time              `json:"finishedAt"`
+	DurationMS int64                  `json:"durationMs"`
+	Sources    []SourceReport         `json:"sources"`
+	Errors     []string               `json:"errors,omitempty"`
+	Created    []model.ScrapedProject `json:"created"`
+	// Alerts holds the rendered alert texts a dry run would have sent.
+	Alerts []string `json:"alerts,omitempty"`
+}
+
+// SourceReport holds one provider's counters for a run. Skipped is set when the provider
+// was not scraped at all, e.g. during backoff or because robots.txt disallows it. Dropped
+// counts projects removed by pipeline interceptors, keyed by "stage: reason".
+type SourceReport struct {
+	Source         string                  `json:"source"`
+	Fetched        int                     `json:"fetched"`
+	OverThreshold  int                     `json:"overThreshold"`
+	BelowThreshold int                     `json:"belowThreshold"`
+	Duplicates     int                     `json:"duplicates"`
+	Saved          int                     `json:"saved"`
+	AlertsQueued   int                     `json:"alertsQueued"`
+	AlertsFailed   int                     `json:"alertsFailed,omitempty"`
+	Dropped        map[string]int          `json:"dropped,omitempty"`
+	FailedPages    []string                `json:"failedPages,omitempty"`
+	Skipped        string                  `json:"skipped,omitempty"`
+	Error          string                  `json:"error,omitempty"`
+	Diagnostics    model.ScrapeDiagnostics `json:"diagnostics"`
+}
+
+func newRunID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
+func (r *RunReport) finish(sources map[string]*SourceReport) {
+	r.FinishedAt = time.Now()
+	r.DurationMS = r.FinishedAt.Sub(r.StartedAt).Milliseconds()
+
+	r.Sources = make([]SourceReport, 0, len(sources))
+	for _, src := range sources {
+		r.Sources = append(r.Sources, *src)
+		if src.Error != "" {
+			r.Errors = append(r.Errors, src.Source+": "+src.Error)
+		}
+	}
+	sort.Slice(r.Sources, func(i, j int) bool { return r.Sources[i].Source < r.Sources[j].Source })
+	sort.Strings(r.Errors)
+}
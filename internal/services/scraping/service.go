@@ -3,13 +3,22 @@ package scraping
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"golang.org/x/sync/errgroup"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"ponisha-go/internal/logging"
 	"ponisha-go/internal/model"
 	"ponisha-go/internal/repositories"
+	"ponisha-go/internal/robots"
+	"ponisha-go/internal/tracing"
 )
 
 type Service struct {
@@ -17,131 +26,617 @@ type Service struct {
 	notifier Notifier
 	scrapers []SiteScraper
 
-	mu      sync.Mutex
-	running bool
+	// root is cancelled by Shutdown; every run derives from it so in-flight scrapes stop
+	// when the process does, and inflight lets Shutdown wait for them to unwind.
+	root       context.Context
+	cancelRoot context.CancelFunc
+	inflight   sync.WaitGroup
+	runTimeout time.Duration
+
+	locker    Locker
+	lockScope string
+	replicaID string
+
+	mu           sync.Mutex
+	running      map[string]bool
+	closed       bool
+	lastRun      *RunReport
+	lastBySource map[string]SourceRun
+	locks        map[string]LockState
+
+	layoutBroken map[string]bool
+	health       *healthTracker
+	interceptors map[Stage][]Interceptor
+	metrics      Metrics
+
+	notifyTimeout time.Duration
+	deliveries    *deliveryTracker
+	threshold     atomic.Int64
+}
+
+type Option func(*Service)
+
+func WithHealthPolicy(policy HealthPolicy) Option {
+	return func(s *Service) {
+		s.health = newHealthTracker(policy)
+	}
+}
+
+// WithRunTimeout bounds each run; zero disables the limit.
+func WithRunTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.runTimeout = timeout
+	}
+}
+
+// WithNotifyTimeout bounds how long a run waits for room in the notifier's queue before
+// counting an alert as failed; zero waits for as long as the run lasts.
+func WithNotifyTimeout(timeout time.Duration) Option {
+	return func(s *Service) {
+		s.notifyTimeout = timeout
+	}
+}
+
+// WithThreshold sets the budget, in toman, a project must exceed (model.TomanThreshold by default).
+func WithThreshold(amount int64) Option {
+	return func(s *Service) {
+		s.threshold.Store(amount)
+	}
+}
+
+func WithMetrics(metrics Metrics) Option {
+	return func(s *Service) {
+		s.metrics = metrics
+	}
+}
+
+func NewService(repo repositories.ProjectRepository, notifier Notifier, scrapers []SiteScraper, options ...Option) *Service {
+	root, cancel := context.WithCancel(context.Background())
+	s := &Service{
+		root:          root,
+		cancelRoot:    cancel,
+		repo:          repo,
+		notifier:      notifier,
+		scrapers:      scrapers,
+		running:       map[string]bool{},
+		lastBySource:  map[string]SourceRun{},
+		locks:         map[string]LockState{},
+		layoutBroken:  map[string]bool{},
+		health:        newHealthTracker(DefaultHealthPolicy()),
+		interceptors:  map[Stage][]Interceptor{},
+		metrics:       noopMetrics{},
+		notifyTimeout: 30 * time.Second,
+		deliveries:    newDeliveryTracker(),
+	}
+	s.threshold.Store(model.TomanThreshold)
+	for _, option := range options {
+		option(s)
+	}
+	return s
 }
 
-func NewService(repo repositories.ProjectRepository, notifier Notifier, scrapers []SiteScraper) *Service {
-	return &Service{repo: repo, notifier: notifier, scrapers: scrapers}
+// SourceRun is the most recent report for one provider together with the run it belonged to.
+type SourceRun struct {
+	RunID      string    `json:"runId"`
+	FinishedAt time.Time `json:"finishedAt"`
+	SourceReport
 }
 
-func (s *Service) Run(ctx context.Context) {
+type Status struct {
+	Replica   string           `json:"replica,omitempty"`
+	Running   []string         `json:"running"`
+	Providers []ProviderHealth `json:"providers"`
+	LastRuns  []SourceRun      `json:"lastRuns"`
+	LastRun   *RunReport       `json:"lastRun,omitempty"`
+	Locks     []LockState      `json:"locks,omitempty"`
+	// Deliveries counts project alerts per provider since startup.
+	Deliveries []DeliveryStats `json:"deliveries"`
+}
+
+func (s *Service) Status() Status {
 	s.mu.Lock()
-	if s.running {
-		s.mu.Unlock()
-		log.Println("scrape already running; skipping")
-		return
+	running := make([]string, 0, len(s.running))
+	for source := range s.running {
+		running = append(running, source)
 	}
-	s.running = true
+	lastRuns := make([]SourceRun, 0, len(s.lastBySource))
+	for _, run := range s.lastBySource {
+		lastRuns = append(lastRuns, run)
+	}
+	lastRun := s.lastRun
 	s.mu.Unlock()
 
-	defer func() {
-		s.mu.Lock()
-		s.running = false
-		s.mu.Unlock()
+	sort.Strings(running)
+	sort.Slice(lastRuns, func(i, j int) bool { return lastRuns[i].Source < lastRuns[j].Source })
+	return Status{
+		Replica:    s.replicaID,
+		Running:    running,
+		Providers:  s.health.snapshot(),
+		LastRuns:   lastRuns,
+		LastRun:    lastRun,
+		Locks:      s.lockSnapshot(),
+		Deliveries: s.deliveries.snapshot(),
+	}
+}
+
+// Threshold is the budget, in toman, a project must exceed to pass the filter stage.
+func (s *Service) Threshold() int64 {
+	return s.threshold.Load()
+}
+
+// SetThreshold changes the budget threshold from the next run on. It is not persisted.
+func (s *Service) SetThreshold(amount int64) error {
+	if amount < 0 {
+		return fmt.Errorf("threshold must not be negative: %d", amount)
+	}
+	s.threshold.Store(amount)
+	return nil
+}
+
+// Sources lists the configured providers in scrape order.
+func (s *Service) Sources() []string {
+	sources := make([]string, 0, len(s.scrapers))
+	for _, sc := range s.scrapers {
+		sources = append(sources, sc.Source())
+	}
+	return sources
+}
+
+var (
+	ErrAlreadyRunning = errors.New("scrape already running")
+	ErrShuttingDown   = errors.New("scrape service is shutting down")
+	ErrUnknownSource  = errors.New("unknown source")
+)
+
+// Run scrapes in the background style used by the scheduler: it waits for the run but only
+// logs the outcome. Like RunSync, the run is also cancelled by Shutdown.
+func (s *Service) Run(ctx context.Context, sources ...string) {
+	report, err := s.RunSync(ctx, sources...)
+	if errors.Is(err, ErrAlreadyRunning) || errors.Is(err, ErrShuttingDown) {
+		slog.InfoContext(ctx, "scrape skipped", "reason", err)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "scrape error", "error", err)
+		return
+	}
+	slog.InfoContext(logging.WithRun(ctx, report.RunID), "scrape finished",
+		"duration_ms", report.DurationMS, "created", len(report.Created), "errors", len(report.Errors),
+	)
+}
+
+// RunSync scrapes the given providers (all of them when none are named) and returns the
+// run report. Providers already being scraped by another run are skipped; if that leaves
+// nothing to do it returns ErrAlreadyRunning. The run stops when ctx is done, the run
+// timeout expires or the service shuts down, whichever comes first.
+func (s *Service) RunSync(ctx context.Context, sources ...string) (RunReport, error) {
+	claimed, skipped, err := s.claim(sources)
+	if err != nil {
+		return RunReport{}, err
+	}
+	return s.execute(ctx, newRunID(), claimed, skipped), nil
+}
+
+// Trigger starts a run in the background and returns its ID once the providers have been
+// claimed, so callers learn about unknown sources, a run already in progress or shutdown
+// before anything is scraped. The run is only stopped by the run timeout or Shutdown.
+func (s *Service) Trigger(sources ...string) (string, error) {
+	claimed, skipped, err := s.claim(sources)
+	if err != nil {
+		return "", err
+	}
+	runID := newRunID()
+	go func() {
+		ctx := context.Background()
+		report := s.execute(ctx, runID, claimed, skipped)
+		slog.InfoContext(logging.WithRun(ctx, report.RunID), "scrape finished",
+			"duration_ms", report.DurationMS, "created", len(report.Created), "errors", len(report.Errors),
+		)
 	}()
+	return runID, nil
+}
+
+// claim marks the selected providers as running and registers the run with Shutdown. On
+// success the caller must pass the result to execute.
+func (s *Service) claim(sources []string) ([]SiteScraper, map[string]string, error) {
+	selected, err := s.selectScrapers(sources)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	if err := s.scrape(ctx); err != nil {
-		log.Printf("scrape error: %v", err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, nil, ErrShuttingDown
 	}
+	var claimed []SiteScraper
+	skipped := map[string]string{}
+	for _, sc := range selected {
+		if s.running[sc.Source()] {
+			skipped[sc.Source()] = skippedAlreadyRunning
+			continue
+		}
+		s.running[sc.Source()] = true
+		claimed = append(claimed, sc)
+	}
+	if len(claimed) == 0 {
+		return nil, nil, ErrAlreadyRunning
+	}
+	s.inflight.Add(1)
+	return claimed, skipped, nil
 }
 
-func (s *Service) scrape(ctx context.Context) error {
-	log.Printf("Scraping started")
+func (s *Service) execute(ctx context.Context, runID string, claimed []SiteScraper, skipped map[string]string) RunReport {
+	defer s.inflight.Done()
+
+	runCtx, cancel := s.runContext(ctx)
+	defer cancel()
+	env := runEnv{
+		runID:         runID,
+		repo:          s.repo,
+		notifier:      s.notifier,
+		notifyTimeout: s.notifyTimeout,
+		deliveries:    s.deliveries,
+	}
+	runCtx, span := startRun(runCtx, env)
 
-	type result struct {
-		source   string
-		projects []model.ScrapedProject
+	locked, releaseLocks := s.acquireLocks(runCtx, claimed, skipped)
+	report := s.scrape(runCtx, env, locked, skipped)
+	releaseLocks()
+	endRun(span, report)
+
+	s.mu.Lock()
+	for _, sc := range claimed {
+		delete(s.running, sc.Source())
+	}
+	s.lastRun = &report
+	for _, src := range report.Sources {
+		if src.Skipped == skippedAlreadyRunning {
+			continue
+		}
+		s.lastBySource[src.Source] = SourceRun{RunID: report.RunID, FinishedAt: report.FinishedAt, SourceReport: src}
 	}
+	s.mu.Unlock()
 
-	results := make(chan result, len(s.scrapers))
-	group, gctx := errgroup.WithContext(ctx)
+	return report
+}
 
-	for _, scraper := range s.scrapers {
-		sc := scraper
-		group.Go(func() error {
-			log.Printf("[%s] scraping...", sc.Source())
-			projects, err := sc.Scrape(gctx)
-			if err != nil {
-				log.Printf("[%s] scrape failed: %v", sc.Source(), err)
-				results <- result{source: sc.Source(), projects: []model.ScrapedProject{}}
-				return nil
+// startRun tags ctx with the run ID for logs and starts the run's root span.
+func startRun(ctx context.Context, env runEnv) (context.Context, trace.Span) {
+	ctx = logging.WithRun(ctx, env.runID)
+	return tracing.Start(ctx, "scrape run", trace.WithAttributes(
+		attribute.String("run_id", env.runID),
+		attribute.Bool("dry_run", env.dryRun),
+	))
+}
+
+func endRun(span trace.Span, report RunReport) {
+	span.SetAttributes(attribute.Int("created", len(report.Created)))
+	var err error
+	if len(report.Errors) > 0 {
+		err = errors.New(strings.Join(report.Errors, "; "))
+	}
+	tracing.End(span, err)
+}
+
+func (s *Service) selectScrapers(sources []string) ([]SiteScraper, error) {
+	if len(sources) == 0 {
+		return s.scrapers, nil
+	}
+	selected := make([]SiteScraper, 0, len(sources))
+	for _, source := range sources {
+		found := false
+		for _, sc := range s.scrapers {
+			if sc.Source() == source {
+				selected = append(selected, sc)
+				found = true
+				break
 			}
-			log.Printf("[%s] found %d projects", sc.Source(), len(projects))
-			results <- result{source: sc.Source(), projects: projects}
-			return nil
-		})
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownSource, source)
+		}
 	}
+	return selected, nil
+}
 
-	if err := group.Wait(); err != nil && !errors.Is(err, context.Canceled) {
-		log.Printf("scrape group error: %v", err)
+func (s *Service) runContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	runCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.root, cancel)
+	if s.runTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		runCtx, cancelTimeout = context.WithTimeout(runCtx, s.runTimeout)
+		return runCtx, func() {
+			cancelTimeout()
+			stop()
+			cancel()
+		}
 	}
-	close(results)
+	return runCtx, func() {
+		stop()
+		cancel()
+	}
+}
 
-	stats := map[string]*scrapeStats{}
+// Shutdown refuses new runs, cancels the running one and waits for it to unwind so that
+// nothing touches the database after the pool is closed.
+func (s *Service) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.cancelRoot()
 
-	for res := range results {
-		st := stats[res.source]
-		if st == nil {
-			st = &scrapeStats{}
-			stats[res.source] = st
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for running scrape: %w", ctx.Err())
+	}
+}
+
+// scrapeEvent is either a page of projects or, with done set, the end of one provider's stream.
+// ctx is the provider's context, so work done for its pages shows up under its span.
+type scrapeEvent struct {
+	ctx    context.Context
+	source string
+	page   model.ScrapePage
+	done   bool
+	err    error
+}
+
+const skippedAlreadyRunning = "already running"
+
+// scrape runs scrapers and reports every source in skipped as not scraped, with its reason.
+// Dry runs ignore backoff and leave health and layout tracking untouched.
+func (s *Service) scrape(ctx context.Context, env runEnv, scrapers []SiteScraper, skipped map[string]string) RunReport {
+	report := RunReport{RunID: env.runID, StartedAt: time.Now(), Created: []model.ScrapedProject{}}
+	slog.InfoContext(ctx, "scraping started", "dry_run", env.dryRun)
+
+	pipe := s.newPipeline(env)
+	metrics := s.metrics
+	if env.dryRun {
+		metrics = noopMetrics{}
+	}
+	sources := map[string]*SourceReport{}
+	sourceReport := func(source string) *SourceReport {
+		src := sources[source]
+		if src == nil {
+			src = &SourceReport{Source: source}
+			sources[source] = src
 		}
-		st.fetched += len(res.projects)
+		return src
+	}
 
-		for _, project := range res.projects {
-			if !isAboveThreshold(project) {
-				st.belowThreshold++
-				continue
-			}
-			st.overThreshold++
-
-			saved, created, err := s.repo.CreateIfNotExists(ctx, model.ProjectCreate{
-				Source:     project.Source,
-				ExternalID: project.ExternalID,
-				Title:      project.Title,
-				Link:       project.Link,
-				BudgetText: project.BudgetText,
-				AmountMin:  project.AmountMin,
-				AmountMax:  project.AmountMax,
-			})
-			if err != nil {
-				log.Printf("[%s] insert failed: %v", project.Source, err)
+	for source, reason := range skipped {
+		sourceReport(source).Skipped = reason
+	}
+
+	events := make(chan scrapeEvent, len(scrapers))
+	var wg sync.WaitGroup
+	for _, scraper := range scrapers {
+		if !env.dryRun && !s.health.ready(scraper.Source(), time.Now()) {
+			sourceReport(scraper.Source()).Skipped = "backing off after failures"
+			continue
+		}
+		wg.Add(1)
+		go func(sc SiteScraper) {
+			defer wg.Done()
+			s.streamSource(logging.WithSource(ctx, sc.Source()), sc, events)
+		}(scraper)
+	}
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+
+	for event := range events {
+		src := sourceReport(event.source)
+		srcCtx := event.ctx
+
+		if event.done {
+			metrics.ObserveSource(event.source, time.Since(report.StartedAt), errors.Is(event.err, robots.ErrDisallowed), event.err)
+			observeProjects(metrics, src)
+			if errors.Is(event.err, robots.ErrDisallowed) {
+				slog.InfoContext(srcCtx, "scrape skipped", "reason", event.err)
+				src.Skipped = event.err.Error()
 				continue
 			}
-			if !created {
-				st.duplicates++
-				if project.Source == "karlancer" {
-					log.Printf("[karlancer] duplicate high-budget project: externalId=%s title=%s amountMin=%d amountMax=%d link=%s",
-						project.ExternalID, project.Title, project.AmountMin, project.AmountMax, project.Link,
-					)
+			if event.err != nil {
+				src.Error = event.err.Error()
+				switch {
+				case env.dryRun:
+					slog.WarnContext(srcCtx, "scrape failed", "error", event.err)
+				case srcCtx.Err() != nil || errors.Is(event.err, context.Canceled):
+					// Shutdown or the run timeout stopped the scrape; that says nothing
+					// about the provider, so it does not count towards backoff.
+					slog.WarnContext(srcCtx, "scrape interrupted", "error", event.err)
+				default:
+					s.recordFailure(srcCtx, event.source, event.err)
 				}
 				continue
 			}
-			st.saved++
-			telegramProject := project
-			telegramProject.Source = saved.Source
-			telegramProject.Link = saved.Link
-			s.notifier.SendAlert(telegramProject)
+			slog.InfoContext(srcCtx, "scrape finished", "projects", src.Fetched)
+			if !env.dryRun {
+				s.recordSuccess(srcCtx, event.source)
+				s.checkLayout(srcCtx, event.source, src.Diagnostics)
+			}
+			continue
+		}
+
+		metrics.ObservePage(event.source, event.page.Err)
+		if event.page.Err != nil {
+			pageErr := model.PageError{Page: event.page.Number, Err: event.page.Err}
+			slog.WarnContext(logging.WithPage(srcCtx, pageErr.Page), "page failed", "error", pageErr.Err)
+			src.FailedPages = append(src.FailedPages, pageErr.Error())
+			continue
+		}
+
+		src.Fetched += len(event.page.Projects)
+		src.Diagnostics.Merge(event.page.Diagnostics)
+		for _, project := range event.page.Projects {
+			item := &Item{Project: project, RunID: report.RunID, DryRun: env.dryRun}
+			if pipe.process(logging.WithPage(srcCtx, event.page.Number), item, src) {
+				report.Created = append(report.Created, item.Project)
+			}
 		}
 	}
 
-	for source, st := range stats {
-		log.Printf("[%s] summary: fetched=%d overThreshold=%d saved=%d duplicates=%d belowThreshold=%d",
-			source, st.fetched, st.overThreshold, st.saved, st.duplicates, st.belowThreshold,
+	for source, src := range sources {
+		if src.Skipped != "" {
+			slog.InfoContext(logging.WithSource(ctx, source), "summary: skipped", "reason", src.Skipped)
+			continue
+		}
+		slog.InfoContext(logging.WithSource(ctx, source), "summary",
+			"fetched", src.Fetched, "over_threshold", src.OverThreshold, "saved", src.Saved,
+			"duplicates", src.Duplicates, "below_threshold", src.BelowThreshold, "failed_pages", len(src.FailedPages),
+		)
+		slog.DebugContext(logging.WithSource(ctx, source), "diagnostics",
+			"pages", src.Diagnostics.Pages, "pages_without_payload", src.Diagnostics.PagesWithoutPayload,
+			"items_seen", src.Diagnostics.ItemsSeen, "items_parsed", src.Diagnostics.ItemsParsed,
+			"skipped", src.Diagnostics.Skipped, "strategies", src.Diagnostics.Strategies,
 		)
 	}
 
-	return nil
+	report.finish(sources)
+	var runErr error
+	if len(report.Errors) > 0 {
+		runErr = errors.New(report.Errors[0])
+	}
+	metrics.ObserveRun(runErr)
+	return report
 }
 
-type scrapeStats struct {
-	fetched        int
-	overThreshold  int
-	belowThreshold int
-	duplicates     int
-	saved          int
+func observeProjects(metrics Metrics, src *SourceReport) {
+	metrics.ObserveProjects(src.Source, "fetched", src.Fetched)
+	metrics.ObserveProjects(src.Source, "over_threshold", src.OverThreshold)
+	metrics.ObserveProjects(src.Source, "below_threshold", src.BelowThreshold)
+	metrics.ObserveProjects(src.Source, "duplicate", src.Duplicates)
+	metrics.ObserveProjects(src.Source, "saved", src.Saved)
 }
 
-func isAboveThreshold(p model.ScrapedProject) bool {
-	return model.IsAboveThreshold(p.AmountMin, p.AmountMax)
+// streamSource forwards one provider's pages to events, wrapping non-streaming scrapers
+// as a single page, and always finishes with a done event.
+func (s *Service) streamSource(ctx context.Context, sc SiteScraper, events chan<- scrapeEvent) {
+	source := sc.Source()
+	slog.InfoContext(ctx, "scraping")
+	ctx, span := tracing.Start(ctx, "scrape "+source, trace.WithAttributes(attribute.String("source", source)))
+
+	streamer, ok := sc.(StreamingScraper)
+	if !ok {
+		result, err := sc.Scrape(ctx)
+		if len(result.Projects) > 0 || result.Diagnostics.Pages > 0 {
+			events <- scrapeEvent{ctx: ctx, source: source, page: model.ScrapePage{
+				Source:      source,
+				Projects:    result.Projects,
+				Diagnostics: result.Diagnostics,
+			}}
+		}
+		for _, pageErr := range result.PageErrors {
+			events <- scrapeEvent{ctx: ctx, source: source, page: model.ScrapePage{Source: source, Number: pageErr.Page, Err: pageErr.Err}}
+		}
+		tracing.End(span, err)
+		events <- scrapeEvent{ctx: ctx, source: source, done: true, err: err}
+		return
+	}
+
+	pages := make(chan model.ScrapePage)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- streamer.Stream(ctx, pages)
+		close(pages)
+	}()
+
+	for page := range pages {
+		events <- scrapeEvent{ctx: ctx, source: source, page: page}
+	}
+	err := <-errCh
+	tracing.End(span, err)
+	events <- scrapeEvent{ctx: ctx, source: source, done: true, err: err}
+}
+
+func (s *Service) recordSuccess(ctx context.Context, source string) {
+	if !s.health.recordSuccess(source, time.Now()) {
+		return
+	}
+	slog.InfoContext(ctx, "provider recovered")
+	s.sendSystemAlert(ctx, model.SystemAlert{
+		Kind:    model.AlertProviderRecovered,
+		Source:  source,
+		Message: "scraping succeeded again",
+	})
+}
+
+// recordFailure logs only until the provider is reported down; after that the backoff
+// schedule and the status endpoint carry the information.
+func (s *Service) recordFailure(ctx context.Context, source string, err error) {
+	health, wentDown := s.health.recordFailure(source, err, time.Now())
+	if health.Status != ProviderDown || wentDown {
+		slog.WarnContext(ctx, "scrape failed",
+			"consecutive_failures", health.ConsecutiveFailures, "next_attempt", health.NextAttemptAt.Format(time.RFC3339), "error", err,
+		)
+	}
+	if !wentDown {
+		return
+	}
+	s.sendSystemAlert(ctx, model.SystemAlert{
+		Kind:   model.AlertProviderDown,
+		Source: source,
+		Message: fmt.Sprintf("%d consecutive failures; backing off until %s. last error: %v",
+			health.ConsecutiveFailures, health.NextAttemptAt.Format(time.RFC3339), err,
+		),
+	})
+}
+
+// checkLayout raises a layout alert the first time a source returns pages with nothing parseable,
+// and re-arms once the source parses items again.
+func (s *Service) checkLayout(ctx context.Context, source string, diag model.ScrapeDiagnostics) {
+	broken := diag.LayoutChanged()
+
+	s.mu.Lock()
+	wasBroken := s.layoutBroken[source]
+	s.layoutBroken[source] = broken
+	s.mu.Unlock()
+
+	if !broken {
+		if wasBroken {
+			slog.InfoContext(ctx, "layout recovered", "items_parsed", diag.ItemsParsed)
+		}
+		return
+	}
+
+	slog.WarnContext(ctx, "layout changed",
+		"pages", diag.Pages, "items_seen", diag.ItemsSeen, "payload_found", diag.PayloadFound(), "skipped", diag.Skipped,
+	)
+	if wasBroken {
+		return
+	}
+
+	message := fmt.Sprintf("fetched %d page(s) but parsed 0 of %d items", diag.Pages, diag.ItemsSeen)
+	if !diag.PayloadFound() {
+		message += fmt.Sprintf("; expected payload missing on %d page(s)", diag.PagesWithoutPayload)
+	}
+	if len(diag.Skipped) > 0 {
+		message += fmt.Sprintf("; skipped %v", diag.Skipped)
+	}
+	s.sendSystemAlert(ctx, model.SystemAlert{
+		Kind:    model.AlertLayoutChanged,
+		Source:  source,
+		Message: message,
+	})
+}
+
+// sendSystemAlert queues alert without holding up the run for long; delivery failures are
+// logged by the notifier.
+func (s *Service) sendSystemAlert(ctx context.Context, alert model.SystemAlert) {
+	enqueueCtx, cancel := withTimeout(ctx, s.notifyTimeout)
+	defer cancel()
+	if _, err := s.notifier.SendSystemAlert(enqueueCtx, alert); err != nil {
+		slog.ErrorContext(ctx, "system alert not queued", "kind", alert.Kind, "error", err)
+	}
 }
@@ -0,0 +1,156 @@
+package scraping
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+// stubScraper fails with err, or when block is set, waits for its context to end first.
+type stubScraper struct {
+	source  string
+	err     error
+	block   bool
+	started chan struct{}
+}
+
+func (s *stubScraper) Source() string { return s.source }
+
+func (s *stubScraper) Scrape(ctx context.Context) (model.ScrapeResult, error) {
+	if s.started != nil {
+		close(s.started)
+	}
+	if s.block {
+		<-ctx.Done()
+		return model.ScrapeResult{}, ctx.Err()
+	}
+	return model.ScrapeResult{}, s.err
+}
+
+// alertRecorder is a Notifier that delivers at once and keeps the system alerts.
+type alertRecorder struct {
+	mu     sync.Mutex
+	alerts []model.SystemAlert
+}
+
+func (r *alertRecorder) SendAlert(context.Context, model.ScrapedProject) (*notifiers.Delivery, error) {
+	return notifiers.Delivered(nil), nil
+}
+
+func (r *alertRecorder) SendSystemAlert(_ context.Context, alert model.SystemAlert) (*notifiers.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, alert)
+	return notifiers.Delivered(nil), nil
+}
+
+func (r *alertRecorder) kinds() []model.SystemAlertKind {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var kinds []model.SystemAlertKind
+	for _, alert := range r.alerts {
+		kinds = append(kinds, alert.Kind)
+	}
+	return kinds
+}
+
+func providerHealth(t *testing.T, s *Service, source string) ProviderHealth {
+	t.Helper()
+	for _, health := range s.Status().Providers {
+		if health.Source == source {
+			return health
+		}
+	}
+	return ProviderHealth{Source: source, Status: ProviderHealthy}
+}
+
+func TestFailuresBackOffAndReportProviderDown(t *testing.T) {
+	scraper := &stubScraper{source: "ponisha", err: errors.New("503 Service Unavailable")}
+	alerts := &alertRecorder{}
+	s := NewService(nil, alerts, []SiteScraper{scraper},
+		WithHealthPolicy(HealthPolicy{DownAfter: 2, BaseBackoff: time.Nanosecond, MaxBackoff: time.Nanosecond}))
+
+	for i := 0; i < 2; i++ {
+		time.Sleep(time.Millisecond)
+		if _, err := s.RunSync(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	health := providerHealth(t, s, "ponisha")
+	if health.Status != ProviderDown || health.ConsecutiveFailures != 2 {
+		t.Errorf("health = %+v, want down after 2 failures", health)
+	}
+	if kinds := alerts.kinds(); len(kinds) != 1 || kinds[0] != model.AlertProviderDown {
+		t.Errorf("alerts = %v, want one provider-down alert", kinds)
+	}
+
+	scraper.err = nil
+	time.Sleep(time.Millisecond)
+	if _, err := s.RunSync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if health := providerHealth(t, s, "ponisha"); health.Status != ProviderHealthy || health.ConsecutiveFailures != 0 {
+		t.Errorf("health after success = %+v", health)
+	}
+	if kinds := alerts.kinds(); len(kinds) != 2 || kinds[1] != model.AlertProviderRecovered {
+		t.Errorf("alerts = %v, want provider-recovered last", kinds)
+	}
+}
+
+func TestInterruptedScrapeDoesNotCountAsFailure(t *testing.T) {
+	policy := HealthPolicy{DownAfter: 1, BaseBackoff: time.Hour, MaxBackoff: time.Hour}
+
+	t.Run("run timeout", func(t *testing.T) {
+		scraper := &stubScraper{source: "ponisha", block: true}
+		alerts := &alertRecorder{}
+		s := NewService(nil, alerts, []SiteScraper{scraper}, WithHealthPolicy(policy), WithRunTimeout(10*time.Millisecond))
+
+		report, err := s.RunSync(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(report.Sources) != 1 || report.Sources[0].Error == "" {
+			t.Errorf("report = %+v, want the interrupted source's error", report.Sources)
+		}
+		if health := providerHealth(t, s, "ponisha"); health.ConsecutiveFailures != 0 || !health.NextAttemptAt.IsZero() {
+			t.Errorf("health = %+v, want no failure recorded", health)
+		}
+		if kinds := alerts.kinds(); len(kinds) != 0 {
+			t.Errorf("alerts = %v, want none", kinds)
+		}
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		scraper := &stubScraper{source: "karlancer", block: true, started: make(chan struct{})}
+		alerts := &alertRecorder{}
+		s := NewService(nil, alerts, []SiteScraper{scraper}, WithHealthPolicy(policy))
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := s.RunSync(context.Background())
+			done <- err
+		}()
+		<-scraper.started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := s.Shutdown(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+		if health := providerHealth(t, s, "karlancer"); health.ConsecutiveFailures != 0 || !health.NextAttemptAt.IsZero() {
+			t.Errorf("health = %+v, want no failure recorded", health)
+		}
+		if kinds := alerts.kinds(); len(kinds) != 0 {
+			t.Errorf("alerts = %v, want none", kinds)
+		}
+	})
+}
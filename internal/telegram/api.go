@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ponisha-go/internal/tracing"
+)
+
+// botAPI calls Bot API methods for one bot token.
+type botAPI struct {
+	token  string
+	client *http.Client
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// call posts payload to method and decodes the result into result (when non-nil). A 429
+// returns how long Telegram asked to wait along with the error.
+func (b *botAPI) call(ctx context.Context, method string, payload, result any) (retryAfter time.Duration, err error) {
+	ctx, span := tracing.Start(ctx, "telegram."+method)
+	defer func() { tracing.End(span, err) }()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("https://api.telegram.org/bot%s/%s", b.token, method), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var parsed apiResponse
+	_ = json.NewDecoder(resp.Body).Decode(&parsed)
+
+	if resp.StatusCode == http.StatusTooManyRequests && parsed.Parameters.RetryAfter > 0 {
+		return time.Duration(parsed.Parameters.RetryAfter) * time.Second, fmt.Errorf("rate limited")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || !parsed.OK {
+		return 0, fmt.Errorf("telegram error: %d %s", resp.StatusCode, parsed.Description)
+	}
+	if result != nil && len(parsed.Result) > 0 {
+		if err := json.Unmarshal(parsed.Result, result); err != nil {
+			return 0, fmt.Errorf("decode %s result: %w", method, err)
+		}
+	}
+	return 0, nil
+}
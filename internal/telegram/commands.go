@@ -0,0 +1,336 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaa110/go-persian-calendar"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/providers/common"
+	"ponisha-go/internal/services/scraping"
+)
+
+// Controller is what the bot commands report on and drive: the scrape service plus the
+// scheduler's pause switch.
+type Controller interface {
+	Trigger(sources ...string) (string, error)
+	Status() scraping.Status
+	Threshold() int64
+	SetThreshold(amount int64) error
+	Pause()
+	Resume()
+	Paused() bool
+}
+
+// ProjectQueries reads saved projects; repositories.ProjectQueries implements it.
+type ProjectQueries interface {
+	Latest(ctx context.Context, limit int) ([]model.Project, error)
+	Search(ctx context.Context, query string, limit int) ([]model.Project, error)
+	Stats(ctx context.Context, since time.Time) ([]model.SourceStats, error)
+}
+
+// WithCommands enables the bot commands in chat, the chat ID or @username alerts go to;
+// commands sent anywhere else are ignored. Commands that change what the scraper does are
+// limited to the admins' user IDs; with no admins nobody may use them.
+func WithCommands(chat string, control Controller, projects ProjectQueries, admins []int64) ConsumerOption {
+	return func(c *Consumer) {
+		c.commands = &commands{chat: chat, control: control, projects: projects, admins: admins}
+	}
+}
+
+type commands struct {
+	chat     string
+	control  Controller
+	projects ProjectQueries
+	admins   []int64
+}
+
+type command struct {
+	admin bool
+	run   func(ctx context.Context, args string) string
+}
+
+const (
+	defaultLatest = 5
+	maxResults    = 20
+)
+
+func (cmds *commands) table() map[string]command {
+	return map[string]command{
+		"start":     {run: cmds.help},
+		"help":      {run: cmds.help},
+		"latest":    {run: cmds.latest},
+		"search":    {run: cmds.search},
+		"stats":     {run: cmds.stats},
+		"status":    {run: cmds.status},
+		"threshold": {run: cmds.threshold},
+		"pause":     {admin: true, run: cmds.pause},
+		"resume":    {admin: true, run: cmds.resume},
+		"scrape":    {admin: true, run: cmds.scrape},
+	}
+}
+
+// handleCommand answers a "/name args" message in the chat it came from. Messages that are
+// not commands are ignored.
+func (c *Consumer) handleCommand(ctx context.Context, msg *message) {
+	if c.commands == nil || msg.From == nil || !strings.HasPrefix(msg.Text, "/") {
+		return
+	}
+	name, args, _ := strings.Cut(strings.TrimSpace(msg.Text[1:]), " ")
+	name, _, _ = strings.Cut(name, "@")
+	cmd, ok := c.commands.table()[strings.ToLower(name)]
+	if !ok {
+		return
+	}
+	if !c.commands.inChat(msg.Chat) {
+		slog.DebugContext(ctx, "telegram command from another chat ignored", "command", name, "chat_id", msg.Chat.ID)
+		return
+	}
+
+	var reply string
+	switch {
+	case cmd.admin && !c.commands.isAdmin(msg.From.ID):
+		reply = "⛔ این دستور فقط برای مدیران است."
+	// /threshold with an amount changes the filter, so it is an admin command too.
+	case name == "threshold" && strings.TrimSpace(args) != "" && !c.commands.isAdmin(msg.From.ID):
+		reply = "⛔ تغییر آستانه فقط برای مدیران است."
+	default:
+		reply = cmd.run(ctx, strings.TrimSpace(args))
+	}
+	slog.InfoContext(ctx, "telegram command", "command", name, "user_id", msg.From.ID)
+
+	payload := map[string]any{
+		"chat_id": msg.Chat.ID,
+		"text":    truncateRunes(reply, 4096),
+		"reply_parameters": map[string]any{
+			"message_id":                  msg.MessageID,
+			"allow_sending_without_reply": true,
+		},
+		"link_preview_options": map[string]any{"is_disabled": true},
+	}
+	if _, err := c.bot.call(ctx, "sendMessage", payload, nil); err != nil {
+		slog.WarnContext(ctx, "telegram command reply failed", "command", name, "error", err)
+	}
+}
+
+// inChat reports whether c is the configured chat, given by ID or by @username.
+func (cmds *commands) inChat(c chat) bool {
+	if id, err := strconv.ParseInt(cmds.chat, 10, 64); err == nil {
+		return c.ID == id
+	}
+	return c.Username != "" && strings.EqualFold(strings.TrimPrefix(cmds.chat, "@"), c.Username)
+}
+
+func (cmds *commands) isAdmin(userID int64) bool {
+	return slices.Contains(cmds.admins, userID)
+}
+
+func (cmds *commands) help(context.Context, string) string {
+	return strings.Join([]string{
+		"دستورها:",
+		"/latest [n] — آخرین پروژه‌های ذخیره‌شده",
+		"/search <عبارت> — جستجو در عنوان پروژه‌ها",
+		"/stats — آمار پروژه‌ها به تفکیک منبع",
+		"/status — آخرین اجرا و وضعیت هر منبع",
+		"/threshold [مبلغ] — نمایش یا تغییر آستانه بودجه (تومان)",
+		"/pause، /resume — توقف و ادامه اجرای زمان‌بندی‌شده (مدیران)",
+		"/scrape — اجرای فوری (مدیران)",
+	}, "\n")
+}
+
+func (cmds *commands) latest(ctx context.Context, args string) string {
+	n := defaultLatest
+	if args != "" {
+		parsed, err := parseAmount(args)
+		if err != nil || parsed < 1 {
+			return "استفاده: /latest [n]"
+		}
+		n = int(min(parsed, maxResults))
+	}
+	projects, err := cmds.projects.Latest(ctx, n)
+	if err != nil {
+		slog.ErrorContext(ctx, "latest projects query failed", "error", err)
+		return "⚠️ خواندن پروژه‌ها ممکن نشد."
+	}
+	if len(projects) == 0 {
+		return "هنوز پروژه‌ای ذخیره نشده است."
+	}
+	return formatProjectList(projects)
+}
+
+func (cmds *commands) search(ctx context.Context, args string) string {
+	if args == "" {
+		return "استفاده: /search <عبارت>"
+	}
+	projects, err := cmds.projects.Search(ctx, args, maxResults)
+	if err != nil {
+		slog.ErrorContext(ctx, "project search failed", "error", err)
+		return "⚠️ جستجو ممکن نشد."
+	}
+	if len(projects) == 0 {
+		return fmt.Sprintf("پروژه‌ای با «%s» پیدا نشد.", args)
+	}
+	return formatProjectList(projects)
+}
+
+func (cmds *commands) stats(ctx context.Context, _ string) string {
+	stats, err := cmds.projects.Stats(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		slog.ErrorContext(ctx, "project stats query failed", "error", err)
+		return "⚠️ خواندن آمار ممکن نشد."
+	}
+	lines := []string{"📊 پروژه‌های ذخیره‌شده:"}
+	for _, stat := range stats {
+		line := fmt.Sprintf("• %s: %d (۲۴ ساعت اخیر: %d)", stat.Source, stat.Total, stat.Recent)
+		if !stat.LastCreatedAt.IsZero() {
+			line += "، آخرین: " + formatClock(stat.LastCreatedAt)
+		}
+		lines = append(lines, line)
+	}
+	if len(stats) == 0 {
+		lines = append(lines, "—")
+	}
+
+	deliveries := cmds.control.Status().Deliveries
+	if len(deliveries) > 0 {
+		lines = append(lines, "", "📨 هشدارها از زمان راه‌اندازی:")
+		for _, d := range deliveries {
+			lines = append(lines, fmt.Sprintf("• %s: ارسال‌شده %d، ناموفق %d، در صف %d", d.Source, d.Delivered, d.Failed, d.Pending))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (cmds *commands) status(context.Context, string) string {
+	status := cmds.control.Status()
+	lines := []string{}
+	if cmds.control.Paused() {
+		lines = append(lines, "⏸ اجرای زمان‌بندی‌شده متوقف است.")
+	} else {
+		lines = append(lines, "▶️ اجرای زمان‌بندی‌شده فعال است.")
+	}
+	lines = append(lines, fmt.Sprintf("💰 آستانه بودجه: %s تومان", formatAmount(cmds.control.Threshold())))
+	if len(status.Running) > 0 {
+		lines = append(lines, "🔄 در حال اجرا: "+strings.Join(status.Running, "، "))
+	}
+
+	health := map[string]scraping.ProviderHealth{}
+	for _, h := range status.Providers {
+		health[h.Source] = h
+	}
+	for _, run := range status.LastRuns {
+		line := fmt.Sprintf("\n🌐 %s — %s", run.Source, formatClock(run.FinishedAt))
+		switch {
+		case run.Skipped != "":
+			line += "\nرد شد: " + run.Skipped
+		case run.Error != "":
+			line += "\n❌ " + run.Error
+		default:
+			line += fmt.Sprintf("\nدریافت %d، بالای آستانه %d، ذخیره %d، تکراری %d", run.Fetched, run.OverThreshold, run.Saved, run.Duplicates)
+		}
+		if h, ok := health[run.Source]; ok && h.Status != "" {
+			line += "\nسلامت: " + h.Status
+		}
+		lines = append(lines, line)
+	}
+	if len(status.LastRuns) == 0 {
+		lines = append(lines, "\nهنوز اجرایی ثبت نشده است.")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (cmds *commands) threshold(ctx context.Context, args string) string {
+	if args == "" {
+		return fmt.Sprintf("💰 آستانه بودجه: %s تومان", formatAmount(cmds.control.Threshold()))
+	}
+	amount, err := parseAmount(args)
+	if err != nil {
+		return "استفاده: /threshold <مبلغ به تومان>"
+	}
+	if err := cmds.control.SetThreshold(amount); err != nil {
+		return "⚠️ " + err.Error()
+	}
+	slog.InfoContext(ctx, "budget threshold changed", "threshold", amount)
+	return fmt.Sprintf("✅ آستانه بودجه از اجرای بعدی %s تومان است.", formatAmount(amount))
+}
+
+func (cmds *commands) pause(ctx context.Context, _ string) string {
+	cmds.control.Pause()
+	slog.InfoContext(ctx, "scheduled scrapes paused")
+	return "⏸ اجرای زمان‌بندی‌شده متوقف شد. /resume برای ادامه."
+}
+
+func (cmds *commands) resume(ctx context.Context, _ string) string {
+	cmds.control.Resume()
+	slog.InfoContext(ctx, "scheduled scrapes resumed")
+	return "▶️ اجرای زمان‌بندی‌شده ادامه یافت."
+}
+
+func (cmds *commands) scrape(ctx context.Context, _ string) string {
+	runID, err := cmds.control.Trigger()
+	switch {
+	case errors.Is(err, scraping.ErrAlreadyRunning):
+		return "⏳ اجرای دیگری در جریان است؛ نتیجه با /status."
+	case errors.Is(err, scraping.ErrShuttingDown):
+		return "⚠️ سرویس در حال توقف است."
+	case err != nil:
+		slog.ErrorContext(ctx, "manual scrape failed to start", "error", err)
+		return "⚠️ شروع اجرا ممکن نشد."
+	}
+	return fmt.Sprintf("🔄 اجرا شروع شد (%s)؛ نتیجه با /status.", runID)
+}
+
+func formatProjectList(projects []model.Project) string {
+	entries := make([]string, 0, len(projects))
+	for _, p := range projects {
+		entries = append(entries, fmt.Sprintf("• %s\n💰 %s — %s\n🔗 %s", p.Title, p.BudgetText, p.Source, p.Link))
+	}
+	return strings.Join(entries, "\n\n")
+}
+
+func formatClock(t time.Time) string {
+	if t.IsZero() {
+		return "—"
+	}
+	return ptime.New(t).Format("yyyy/MM/dd HH:mm")
+}
+
+// formatAmount groups digits in threes, e.g. 99,000,000.
+func formatAmount(amount int64) string {
+	digits := strconv.FormatInt(amount, 10)
+	var out []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 && digits[i-1] != '-' {
+			out = append(out, ',')
+		}
+		out = append(out, digits[i])
+	}
+	return string(out)
+}
+
+// parseAmount reads a number typed with Persian, Arabic-Indic or ASCII digits, ignoring
+// thousands separators, so "۱۵۰,۰۰۰,۰۰۰" parses like "150000000".
+func parseAmount(value string) (int64, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == ',' || r == '_' || r == '٬' || r == '،' {
+			return -1
+		}
+		return r
+	}, common.NormalizeDigits(strings.TrimSpace(value)))
+	return strconv.ParseInt(digits, 10, 64)
+}
+
+func truncateRunes(value string, limit int) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	return string(runes[:limit-1]) + "…"
+}
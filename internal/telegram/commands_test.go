@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/services/scraping"
+)
+
+// control is a Controller that only keeps the threshold.
+type control struct {
+	threshold int64
+}
+
+func (c *control) Trigger(...string) (string, error) { return "run-1", nil }
+func (c *control) Status() scraping.Status           { return scraping.Status{} }
+func (c *control) Threshold() int64                  { return c.threshold }
+func (c *control) SetThreshold(amount int64) error   { c.threshold = amount; return nil }
+func (c *control) Pause()                            {}
+func (c *control) Resume()                           {}
+func (c *control) Paused() bool                      { return false }
+
+type noProjects struct{}
+
+func (noProjects) Latest(context.Context, int) ([]model.Project, error)         { return nil, nil }
+func (noProjects) Search(context.Context, string, int) ([]model.Project, error) { return nil, nil }
+func (noProjects) Stats(context.Context, time.Time) ([]model.SourceStats, error) {
+	return nil, nil
+}
+
+func newTestConsumer(t *testing.T, bot *botServer, chat string, ctl Controller) *Consumer {
+	t.Helper()
+	srv := httptest.NewServer(bot)
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	return NewConsumer("token", nil,
+		WithConsumerHTTPClient(&http.Client{Transport: redirect{target}}),
+		WithCommands(chat, ctl, noProjects{}, []int64{7}),
+	)
+}
+
+func TestCommandsOnlyAnswerTheConfiguredChat(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		chat     chat
+		answered bool
+	}{
+		{"configured chat ID", "-100", chat{ID: -100}, true},
+		{"other group", "-100", chat{ID: -200}, false},
+		{"private chat", "-100", chat{ID: 7}, false},
+		{"configured username", "@alerts", chat{ID: -300, Username: "Alerts"}, true},
+		{"other username", "@alerts", chat{ID: -400, Username: "others"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bot := &botServer{}
+			c := newTestConsumer(t, bot, tt.config, &control{threshold: 99_000_000})
+
+			c.handleCommand(context.Background(), &message{MessageID: 1, From: &user{ID: 7}, Chat: tt.chat, Text: "/threshold"})
+
+			bot.mu.Lock()
+			defer bot.mu.Unlock()
+			if answered := len(bot.texts) > 0; answered != tt.answered {
+				t.Errorf("answered = %v, want %v (replies %q)", answered, tt.answered, bot.texts)
+			}
+		})
+	}
+}
+
+func TestThresholdCommandReadsPersianDigits(t *testing.T) {
+	bot := &botServer{}
+	ctl := &control{threshold: 99_000_000}
+	c := newTestConsumer(t, bot, "-100", ctl)
+
+	c.handleCommand(context.Background(), &message{MessageID: 1, From: &user{ID: 7}, Chat: chat{ID: -100}, Text: "/threshold ۱۵۰٬۰۰۰٬۰۰۰"})
+
+	if ctl.threshold != 150_000_000 {
+		t.Errorf("threshold = %d, want 150,000,000", ctl.threshold)
+	}
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if len(bot.texts) != 1 || !strings.Contains(bot.texts[0], "150,000,000") {
+		t.Errorf("replies = %q", bot.texts)
+	}
+}
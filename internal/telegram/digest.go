@@ -0,0 +1,107 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/yaa110/go-persian-calendar"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+const (
+	// maxMessageLength is Telegram's limit on message text, counted in UTF-16 code units.
+	maxMessageLength = 4096
+	// partHeaderRoom is kept free in every part for the "(k/n)" continuation header.
+	partHeaderRoom = 64
+	// Titles, budgets and links are capped so a single line always fits in one message.
+	maxDigestTitle  = 120
+	maxDigestBudget = 60
+	maxDigestLink   = 2048
+)
+
+// formatDigest renders the digest as HTML messages of one compact line per project. Lines are
+// never split, so every part stays under Telegram's limit even with the markup counted. It
+// also returns how many projects each part lists.
+func formatDigest(digest model.Digest) ([]string, []int) {
+	header := fmt.Sprintf("🗞 خلاصه پروژه‌ها: %d پروژه\n🕘 %s تا %s",
+		len(digest.Projects), formatDigestTime(digest.From), formatDigestTime(digest.To))
+
+	lines := make([]string, 0, len(digest.Projects))
+	for i, project := range digest.Projects {
+		lines = append(lines, formatDigestLine(i+1, project))
+	}
+
+	chunks, projects := packLines(lines, maxMessageLength-partHeaderRoom-utf16Len(header))
+	parts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		switch {
+		case len(chunks) == 1:
+			parts[i] = header + "\n\n" + chunk
+		case i == 0:
+			parts[i] = fmt.Sprintf("%s (%d/%d)\n\n%s", header, i+1, len(chunks), chunk)
+		default:
+			parts[i] = fmt.Sprintf("🗞 ادامه خلاصه (%d/%d)\n\n%s", i+1, len(chunks), chunk)
+		}
+	}
+	return parts, projects
+}
+
+func formatDigestLine(n int, project model.ScrapedProject) string {
+	title := html.EscapeString(truncateRunes(project.Title, maxDigestTitle))
+	if project.Link != "" && len(project.Link) <= maxDigestLink {
+		title = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(project.Link), title)
+	}
+	budget := html.EscapeString(truncateRunes(project.BudgetText, maxDigestBudget))
+	line := fmt.Sprintf("%d. %s\n💰 %s · %s", n, title, budget, html.EscapeString(project.Source))
+	if project.BidsCount != nil {
+		line += fmt.Sprintf(" · 📦 %d", *project.BidsCount)
+	}
+	return line
+}
+
+// packLines joins lines with blank lines between them into chunks of at most limit UTF-16
+// code units, assuming no single line is longer than limit, and counts the lines in each.
+func packLines(lines []string, limit int) ([]string, []int) {
+	var chunks []string
+	var counts []int
+	var current strings.Builder
+	size, count := 0, 0
+	for _, line := range lines {
+		length := utf16Len(line)
+		if size > 0 && size+2+length > limit {
+			chunks = append(chunks, current.String())
+			counts = append(counts, count)
+			current.Reset()
+			size, count = 0, 0
+		}
+		if size > 0 {
+			current.WriteString("\n\n")
+			size += 2
+		}
+		current.WriteString(line)
+		size += length
+		count++
+	}
+	if size > 0 {
+		chunks = append(chunks, current.String())
+		counts = append(counts, count)
+	}
+	return chunks, counts
+}
+
+func formatDigestTime(t time.Time) string {
+	return ptime.New(t.In(notifiers.Tehran)).Format("yyyy/MM/dd HH:mm")
+}
+
+func utf16Len(value string) int {
+	n := 0
+	for _, r := range value {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,114 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
+)
+
+type inlineKeyboard struct {
+	InlineKeyboard [][]inlineButton `json:"inline_keyboard"`
+}
+
+type inlineButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+// Telegram limits callback data to 64 bytes.
+const maxCallbackData = 64
+
+// triageAction is the button pressed; it is encoded in the callback data as
+// "tr:<action>:<source>:<externalID>[:<deadline unix>]".
+type triageAction struct {
+	State      model.TriageState
+	Source     string
+	ExternalID string
+	// Deadline is only sent with the Remind button.
+	Deadline time.Time
+}
+
+var stateCodes = map[model.TriageState]string{
+	model.TriageInterested: "i",
+	model.TriageDismissed:  "d",
+	model.TriageRemind:     "r",
+}
+
+func (a triageAction) encode() string {
+	data := fmt.Sprintf("tr:%s:%s:%s", stateCodes[a.State], a.Source, a.ExternalID)
+	if !a.Deadline.IsZero() {
+		data += ":" + strconv.FormatInt(a.Deadline.Unix(), 10)
+	}
+	return data
+}
+
+func parseTriageAction(data string) (triageAction, bool) {
+	fields := strings.Split(data, ":")
+	if len(fields) < 4 || fields[0] != "tr" {
+		return triageAction{}, false
+	}
+	action := triageAction{Source: fields[2], ExternalID: fields[3]}
+	for state, code := range stateCodes {
+		if code == fields[1] {
+			action.State = state
+		}
+	}
+	if action.State == "" {
+		return triageAction{}, false
+	}
+	if len(fields) > 4 {
+		unix, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return triageAction{}, false
+		}
+		action.Deadline = time.Unix(unix, 0)
+	}
+	return action, true
+}
+
+// projectKeyboard returns the triage buttons for project. The Remind button is left out when
+// the project has no deadline; buttons whose callback data would be too long are left out too.
+func projectKeyboard(project model.ScrapedProject) *inlineKeyboard {
+	var row []inlineButton
+	if project.Link != "" {
+		row = append(row, inlineButton{Text: "🔗 مشاهده", URL: project.Link})
+	}
+
+	type button struct {
+		label  string
+		action triageAction
+	}
+	actions := []button{
+		{"⭐ علاقه‌مندم", triageAction{State: model.TriageInterested}},
+		{"🚫 رد", triageAction{State: model.TriageDismissed}},
+	}
+	if deadline, err := notifiers.ParseTime(project.BiddingClosedAt); err == nil && deadline.After(time.Now()) {
+		actions = append(actions, button{"⏰ یادآوری", triageAction{State: model.TriageRemind, Deadline: deadline}})
+	}
+
+	var triageRow []inlineButton
+	for _, a := range actions {
+		a.action.Source = project.Source
+		a.action.ExternalID = project.ExternalID
+		if data := a.action.encode(); len(data) <= maxCallbackData {
+			triageRow = append(triageRow, inlineButton{Text: a.label, CallbackData: data})
+		}
+	}
+
+	keyboard := &inlineKeyboard{}
+	if len(row) > 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	}
+	if len(triageRow) > 0 {
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, triageRow)
+	}
+	if len(keyboard.InlineKeyboard) == 0 {
+		return nil
+	}
+	return keyboard
+}
@@ -1,16 +1,17 @@
 package telegram
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
+	"html"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/yaa110/go-persian-calendar"
 
 	"ponisha-go/internal/model"
+	"ponisha-go/internal/notifiers"
 )
 
 type Sender struct {
@@ -18,115 +19,176 @@ type Sender struct {
 	chat     string
 	threadID *int
 
-	client       *http.Client
-	queue        chan string
-	minInterval  time.Duration
-	lastSentTime time.Time
+	client    *http.Client
+	bot       *botAPI
+	queue     *notifiers.Queue[outgoing]
+	queueSize int
+	throttle  *notifiers.Throttle
+	triage    bool
+
+	metrics Metrics
 }
 
-func NewSender(token, chat string, threadID *int) *Sender {
-	s := &Sender{
-		token:       token,
-		chat:        chat,
-		threadID:    threadID,
-		client:      &http.Client{Timeout: 15 * time.Second},
-		queue:       make(chan string, 100),
-		minInterval: 1200 * time.Millisecond,
+// Metrics receives the latency and outcome of every delivered message.
+type Metrics interface {
+	ObserveSend(notifier string, duration time.Duration, err error)
+}
+
+type Option func(*Sender)
+
+func WithMetrics(metrics Metrics) Option {
+	return func(s *Sender) {
+		s.metrics = metrics
 	}
+}
 
-	go s.worker()
-	return s
+// WithHTTPClient replaces the default client used to call the Bot API.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Sender) {
+		s.client = client
+	}
 }
 
-func (s *Sender) SendAlert(project model.ScrapedProject) {
-	message := formatMessage(project)
-	for _, part := range splitMessage(message, 4096) {
-		s.queue <- part
+// WithTriageButtons adds the Open, Interested, Dismiss and Remind buttons to project alerts.
+// A Consumer must be running to handle the button presses.
+func WithTriageButtons() Option {
+	return func(s *Sender) {
+		s.triage = true
 	}
 }
 
-func (s *Sender) worker() {
-	for msg := range s.queue {
-		s.sendWithRateLimit(msg)
+// WithQueueSize sets how many messages may wait to be sent (100 by default).
+func WithQueueSize(size int) Option {
+	return func(s *Sender) {
+		s.queueSize = size
 	}
 }
 
-func (s *Sender) sendWithRateLimit(text string) {
-	wait := time.Until(s.lastSentTime.Add(s.minInterval))
-	if wait > 0 {
-		time.Sleep(wait)
+func NewSender(token, chat string, threadID *int, options ...Option) *Sender {
+	s := &Sender{
+		token:     token,
+		chat:      chat,
+		threadID:  threadID,
+		client:    &http.Client{Timeout: 15 * time.Second},
+		queueSize: 100,
+	}
+	for _, option := range options {
+		option(s)
 	}
+	s.bot = &botAPI{token: token, client: s.client}
+	s.throttle = &notifiers.Throttle{MinInterval: 1200 * time.Millisecond, Observe: s.observe}
 
-	retryAfter, err := s.postMessage(text)
-	if err != nil {
-		if retryAfter > 0 {
-			log.Printf("Telegram rate limit hit. Retrying after %s", retryAfter)
-			time.Sleep(retryAfter)
-			if _, retryErr := s.postMessage(text); retryErr != nil {
-				log.Printf("Telegram retry failed: %v", retryErr)
-				return
-			}
-			s.lastSentTime = time.Now()
-			log.Printf("Telegram alert sent successfully (after retry)")
-			return
-		}
+	s.queue = notifiers.NewQueue(s.queueSize, s.sendParts)
+	return s
+}
 
-		log.Printf("Telegram send error: %v", err)
-		return
+// SendAlert queues the alert, waiting for room in the queue until ctx is done. The returned
+// Delivery completes once Telegram has accepted every part of the message.
+func (s *Sender) SendAlert(ctx context.Context, project model.ScrapedProject) (*notifiers.Delivery, error) {
+	msg := outgoing{parts: splitMessage(formatMessage(project), 4096)}
+	if s.triage {
+		msg.keyboard = projectKeyboard(project)
 	}
+	return s.enqueue(ctx, msg)
+}
 
-	s.lastSentTime = time.Now()
-	log.Printf("Telegram alert sent successfully")
+func (s *Sender) RenderAlert(project model.ScrapedProject) string {
+	return formatMessage(project)
 }
 
-func (s *Sender) postMessage(text string) (time.Duration, error) {
-	payload := map[string]any{
-		"chat_id":    s.chat,
-		"text":       text,
-		"parse_mode": "HTML",
-	}
-	if s.threadID != nil {
-		payload["message_thread_id"] = *s.threadID
-	}
+func (s *Sender) SendSystemAlert(ctx context.Context, alert model.SystemAlert) (*notifiers.Delivery, error) {
+	return s.enqueue(ctx, outgoing{parts: []string{formatSystemAlert(alert)}})
+}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return 0, err
-	}
+// QueueDepth is the number of messages waiting to be sent.
+func (s *Sender) QueueDepth() int {
+	return s.queue.Len()
+}
 
-	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", s.token), bytes.NewReader(body))
-	if err != nil {
-		return 0, err
+// outgoing is one alert split into message parts; the keyboard goes on the last part.
+type outgoing struct {
+	parts     []string
+	keyboard  *inlineKeyboard
+	noPreview bool
+}
+
+// SendDigest queues the digest as one compact message, split into numbered parts as needed
+// and without link previews. Each part is queued on its own, so when one fails only its
+// projects are kept for the next digest.
+func (s *Sender) SendDigest(ctx context.Context, digest model.Digest) (*notifiers.Delivery, error) {
+	parts, projects := formatDigest(digest)
+	messages := make([]outgoing, len(parts))
+	for i, part := range parts {
+		messages[i] = outgoing{parts: []string{part}, noPreview: true}
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return notifiers.QueueDigest(ctx, messages, projects, s.enqueue)
+}
 
-	resp, err := s.client.Do(req)
+func (s *Sender) enqueue(ctx context.Context, msg outgoing) (*notifiers.Delivery, error) {
+	delivery, err := s.queue.Enqueue(ctx, msg)
 	if err != nil {
-		return 0, err
+		slog.WarnContext(ctx, "telegram message not queued", "error", err)
+		return nil, fmt.Errorf("telegram: %w", err)
 	}
-	defer resp.Body.Close()
+	return delivery, nil
+}
 
-	var parsed telegramResponse
-	_ = json.NewDecoder(resp.Body).Decode(&parsed)
+// Close stops accepting alerts and waits until the queued messages have been sent.
+func (s *Sender) Close(ctx context.Context) error {
+	return s.queue.Close(ctx)
+}
 
-	if resp.StatusCode == http.StatusTooManyRequests && parsed.Parameters.RetryAfter > 0 {
-		return time.Duration(parsed.Parameters.RetryAfter) * time.Second, fmt.Errorf("rate limited")
+func (s *Sender) sendParts(ctx context.Context, msg outgoing) error {
+	for i, part := range msg.parts {
+		var keyboard *inlineKeyboard
+		if i == len(msg.parts)-1 {
+			keyboard = msg.keyboard
+		}
+		if err := s.sendWithRateLimit(ctx, part, keyboard, msg.noPreview); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return 0, fmt.Errorf("telegram error: %d %s", resp.StatusCode, parsed.Description)
+func (s *Sender) sendWithRateLimit(ctx context.Context, text string, keyboard *inlineKeyboard, noPreview bool) error {
+	retried, err := s.throttle.Do(ctx, func(ctx context.Context) (time.Duration, error) {
+		retryAfter, err := s.postMessage(ctx, text, keyboard, noPreview)
+		if retryAfter > 0 {
+			slog.WarnContext(ctx, "telegram rate limit hit; retrying", "retry_after", retryAfter)
+		}
+		return retryAfter, err
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "telegram send error", "error", err, "retried", retried)
+		return err
 	}
+	slog.InfoContext(ctx, "telegram alert sent", "retried", retried)
+	return nil
+}
 
-	return 0, nil
+func (s *Sender) observe(duration time.Duration, err error) {
+	if s.metrics != nil {
+		s.metrics.ObserveSend("telegram", duration, err)
+	}
 }
 
-type telegramResponse struct {
-	OK          bool   `json:"ok"`
-	ErrorCode   int    `json:"error_code"`
-	Description string `json:"description"`
-	Parameters  struct {
-		RetryAfter int `json:"retry_after"`
-	} `json:"parameters"`
+func (s *Sender) postMessage(ctx context.Context, text string, keyboard *inlineKeyboard, noPreview bool) (retryAfter time.Duration, err error) {
+	payload := map[string]any{
+		"chat_id":    s.chat,
+		"text":       text,
+		"parse_mode": "HTML",
+	}
+	if s.threadID != nil {
+		payload["message_thread_id"] = *s.threadID
+	}
+	if keyboard != nil {
+		payload["reply_markup"] = keyboard
+	}
+	if noPreview {
+		payload["link_preview_options"] = map[string]any{"is_disabled": true}
+	}
+	return s.bot.call(ctx, "sendMessage", payload, nil)
 }
 
 func formatMessage(project model.ScrapedProject) string {
@@ -138,9 +200,10 @@ func formatMessage(project model.ScrapedProject) string {
 	approvedAt := formatPersianTime(project.ApprovedAt)
 	biddingClosedAt := formatPersianTime(project.BiddingClosedAt)
 
-	message := fmt.Sprintf("📢 %s\n🌐 منبع: %s\n💰 بودجه: %s\n", project.Title, project.Source, project.BudgetText)
+	message := fmt.Sprintf("📢 %s\n🌐 منبع: %s\n💰 بودجه: %s\n",
+		html.EscapeString(project.Title), html.EscapeString(project.Source), html.EscapeString(project.BudgetText))
 	if project.Description != "" {
-		message += fmt.Sprintf("📝 توضیحات: %s\n", project.Description)
+		message += fmt.Sprintf("📝 توضیحات: %s\n", html.EscapeString(project.Description))
 	}
 	message += fmt.Sprintf("🛠 مهارت‌ها: %s\n", skillList)
 	if approvedAt != "" {
@@ -152,15 +215,31 @@ func formatMessage(project model.ScrapedProject) string {
 	if project.BidsCount != nil {
 		message += fmt.Sprintf("📦 تعداد پیشنهادها: %d\n", *project.BidsCount)
 	}
-	message += fmt.Sprintf("🔗 لینک: %s", project.Link)
+	if len(project.Annotations) > 0 {
+		message += fmt.Sprintf("🏷 برچسب‌ها: %s\n", html.EscapeString(notifiers.FormatAnnotations(project.Annotations)))
+	}
+	message += fmt.Sprintf("🔗 لینک: %s", html.EscapeString(project.Link))
 	return message
 }
 
+func formatSystemAlert(alert model.SystemAlert) string {
+	title := "هشدار سیستم"
+	switch alert.Kind {
+	case model.AlertLayoutChanged:
+		title = "تغییر ساختار صفحه"
+	case model.AlertProviderDown:
+		title = "🔴 منبع از دسترس خارج شد"
+	case model.AlertProviderRecovered:
+		title = "🟢 منبع دوباره در دسترس است"
+	}
+	return fmt.Sprintf("⚠️ %s\n🌐 منبع: %s\n📝 %s", title, html.EscapeString(alert.Source), html.EscapeString(alert.Message))
+}
+
 func formatPersianTime(value string) string {
 	if value == "" {
 		return ""
 	}
-	parsed, err := parseTime(value)
+	parsed, err := notifiers.ParseTime(value)
 	if err != nil {
 		return ""
 	}
@@ -168,20 +247,6 @@ func formatPersianTime(value string) string {
 	return pt.Format("yyyy/MM/dd HH:mm")
 }
 
-func parseTime(value string) (time.Time, error) {
-	layouts := []string{
-		time.RFC3339,
-		time.RFC3339Nano,
-		"2006-01-02 15:04:05",
-	}
-	for _, layout := range layouts {
-		if parsed, err := time.Parse(layout, value); err == nil {
-			return parsed, nil
-		}
-	}
-	return time.Time{}, fmt.Errorf("unsupported time format: %s", value)
-}
-
 func joinSkills(skills []string) string {
 	if len(skills) == 0 {
 		return "—"
@@ -191,7 +256,7 @@ func joinSkills(skills []string) string {
 		if i > 0 {
 			out += ", "
 		}
-		out += skill
+		out += html.EscapeString(skill)
 	}
 	return out
 }
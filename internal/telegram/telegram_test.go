@@ -0,0 +1,127 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+var (
+	allowedTags = regexp.MustCompile(`</?(b|i|u|s|code|pre)>|<a href="[^"<>]*">|</a>`)
+	entity      = regexp.MustCompile(`&(amp|lt|gt|quot|#\d+);`)
+)
+
+// botServer is a stand-in Bot API that, like Telegram, rejects HTML messages with stray
+// '<', '>' or '&', and records the texts it accepted.
+type botServer struct {
+	mu    sync.Mutex
+	texts []string
+}
+
+func (b *botServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Text      string `json:"text"`
+		ParseMode string `json:"parse_mode"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if payload.ParseMode == "HTML" {
+		stripped := entity.ReplaceAllString(allowedTags.ReplaceAllString(payload.Text, ""), "")
+		if strings.ContainsAny(stripped, "<>&") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities"}`))
+			return
+		}
+	}
+	b.mu.Lock()
+	b.texts = append(b.texts, payload.Text)
+	b.mu.Unlock()
+	_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+}
+
+// redirect sends every request to target, standing in for api.telegram.org.
+type redirect struct {
+	target *url.URL
+}
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newTestSender(t *testing.T, bot *botServer) *Sender {
+	t.Helper()
+	srv := httptest.NewServer(bot)
+	t.Cleanup(srv.Close)
+	target, _ := url.Parse(srv.URL)
+	s := NewSender("token", "-100", nil, WithHTTPClient(&http.Client{Transport: redirect{target}}))
+	t.Cleanup(func() { s.Close(context.Background()) })
+	return s
+}
+
+func TestSendSystemAlertEscapesHTML(t *testing.T) {
+	bot := &botServer{}
+	s := newTestSender(t, bot)
+
+	alert := model.SystemAlert{
+		Kind:    model.AlertProviderDown,
+		Source:  "ponisha<beta>",
+		Message: `fetch "https://ponisha.ir/search?a=1&b=2": map[status:<nil>]`,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delivery, err := s.SendSystemAlert(ctx, alert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := delivery.Wait(ctx); err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if len(bot.texts) != 1 {
+		t.Fatalf("got %d messages, want 1", len(bot.texts))
+	}
+	shown := html.UnescapeString(bot.texts[0])
+	if !strings.Contains(shown, alert.Message) || !strings.Contains(shown, alert.Source) {
+		t.Errorf("message %q does not show the alert as written", shown)
+	}
+}
+
+func TestSendAlertEscapesHTML(t *testing.T) {
+	bot := &botServer{}
+	s := newTestSender(t, bot)
+
+	project := model.ScrapedProject{
+		Source:      "karlancer",
+		Title:       `C# & .NET <Core> "API"`,
+		Description: "a < b && c > d",
+		BudgetText:  "تا ۲۰ میلیون تومان",
+		Skills:      []string{"C#", "<html>"},
+		Link:        "https://www.karlancer.com/project/1?ref=a&x=1",
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	delivery, err := s.SendAlert(ctx, project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := delivery.Wait(ctx); err != nil {
+		t.Fatalf("delivery failed: %v", err)
+	}
+}
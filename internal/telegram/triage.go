@@ -0,0 +1,50 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	"ponisha-go/internal/model"
+)
+
+var stateLabels = map[model.TriageState]string{
+	model.TriageInterested: "⭐ علاقه‌مند",
+	model.TriageRemind:     "⏰ یادآوری",
+	model.TriageDismissed:  "🚫 رد شده",
+}
+
+// statusMarker starts the triage section appended to an alert; editing replaces everything
+// after it.
+const statusMarker = "\n\n📋 وضعیت:"
+
+// withTriageStatus appends who chose what to the alert text, replacing an earlier status.
+func withTriageStatus(text string, all []model.Triage) string {
+	if i := strings.Index(text, statusMarker); i >= 0 {
+		text = text[:i]
+	}
+
+	byState := map[model.TriageState][]string{}
+	for _, t := range all {
+		byState[t.State] = append(byState[t.State], t.UserName)
+	}
+	status := statusMarker
+	for _, state := range []model.TriageState{model.TriageInterested, model.TriageRemind, model.TriageDismissed} {
+		if names := byState[state]; len(names) > 0 {
+			status += fmt.Sprintf("\n%s: %s", stateLabels[state], strings.Join(names, "، "))
+		}
+	}
+
+	if over := len([]rune(text)) + len([]rune(status)) - 4096; over > 0 {
+		runes := []rune(text)
+		text = string(runes[:max(len(runes)-over-1, 0)]) + "…"
+	}
+	return text + status
+}
+
+func formatReminder(t model.Triage) string {
+	message := fmt.Sprintf("⏰ %s، یادآوری: مهلت پیشنهاد برای «%s» به‌زودی تمام می‌شود.", t.UserName, t.Title)
+	if t.Link != "" {
+		message += "\n🔗 " + t.Link
+	}
+	return message
+}
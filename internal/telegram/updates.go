@@ -0,0 +1,333 @@
+package telegram
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ponisha-go/internal/model"
+)
+
+// TriageStore keeps what users chose on alerts; repositories.TriageRepository implements it.
+type TriageStore interface {
+	Set(ctx context.Context, triage model.Triage) error
+	ListByProject(ctx context.Context, source, externalID string) ([]model.Triage, error)
+	ClaimDueReminders(ctx context.Context, now time.Time, limit int) ([]model.Triage, error)
+}
+
+// Consumer receives bot updates, either by long-polling getUpdates or through a webhook,
+// records button presses on alerts, sends the reminders users asked for and, with
+// WithCommands, answers bot commands.
+type Consumer struct {
+	bot      *botAPI
+	store    TriageStore
+	commands *commands
+
+	webhookURL    string
+	webhookSecret string
+	remindBefore  time.Duration
+	remindEvery   time.Duration
+	offset        int64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type ConsumerOption func(*Consumer)
+
+// WithWebhook makes Start register url with Telegram instead of polling; updates then arrive
+// through Handler. Telegram sends secret in the X-Telegram-Bot-Api-Secret-Token header, and
+// Handler rejects every request without it.
+func WithWebhook(url, secret string) ConsumerOption {
+	return func(c *Consumer) {
+		c.webhookURL = url
+		c.webhookSecret = secret
+	}
+}
+
+// WithRemindBefore sets how long before bidding closes a reminder is sent (2h by default).
+func WithRemindBefore(d time.Duration) ConsumerOption {
+	return func(c *Consumer) {
+		c.remindBefore = d
+	}
+}
+
+// WithConsumerHTTPClient replaces the client used to call the Bot API. Its timeout must be
+// longer than the 30s long-poll.
+func WithConsumerHTTPClient(client *http.Client) ConsumerOption {
+	return func(c *Consumer) {
+		c.bot.client = client
+	}
+}
+
+func NewConsumer(token string, store TriageStore, options ...ConsumerOption) *Consumer {
+	c := &Consumer{
+		bot:          &botAPI{token: token, client: &http.Client{Timeout: 45 * time.Second}},
+		store:        store,
+		remindBefore: 2 * time.Hour,
+		remindEvery:  time.Minute,
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+const pollTimeout = 30
+
+var allowedUpdates = []string{"message", "callback_query"}
+
+// Start registers or removes the webhook and starts polling (without a webhook) and the
+// reminder loop. They run until Stop.
+func (c *Consumer) Start(ctx context.Context) error {
+	if c.webhookURL != "" {
+		payload := map[string]any{"url": c.webhookURL, "allowed_updates": allowedUpdates}
+		payload["secret_token"] = c.webhookSecret
+		if _, err := c.bot.call(ctx, "setWebhook", payload, nil); err != nil {
+			return err
+		}
+	} else if _, err := c.bot.call(ctx, "deleteWebhook", map[string]any{}, nil); err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	c.cancel = cancel
+	if c.webhookURL == "" {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			c.poll(runCtx)
+		}()
+	}
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.remind(runCtx)
+	}()
+	slog.InfoContext(ctx, "telegram updates started", "webhook", c.webhookURL != "")
+	return nil
+}
+
+// Stop ends polling and reminders, waiting for them until ctx is done.
+func (c *Consumer) Stop(ctx context.Context) error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Handler receives webhook updates. Requests whose X-Telegram-Bot-Api-Secret-Token does not
+// match the WithWebhook secret get 401; without a secret every request does.
+func (c *Consumer) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		token := r.Header.Get("X-Telegram-Bot-Api-Secret-Token")
+		if c.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(c.webhookSecret)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var u update
+		if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		c.handle(r.Context(), u)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func (c *Consumer) poll(ctx context.Context) {
+	for ctx.Err() == nil {
+		var updates []update
+		payload := map[string]any{"offset": c.offset, "timeout": pollTimeout, "allowed_updates": allowedUpdates}
+		if _, err := c.bot.call(ctx, "getUpdates", payload, &updates); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.WarnContext(ctx, "telegram getUpdates failed", "error", err)
+			if !sleepCtx(ctx, 5*time.Second) {
+				return
+			}
+			continue
+		}
+		for _, u := range updates {
+			c.handle(ctx, u)
+			c.offset = u.UpdateID + 1
+		}
+	}
+}
+
+func (c *Consumer) handle(ctx context.Context, u update) {
+	switch {
+	case u.CallbackQuery != nil:
+		c.handleCallback(ctx, u.CallbackQuery)
+	case u.Message != nil:
+		c.handleCommand(ctx, u.Message)
+	}
+}
+
+func (c *Consumer) handleCallback(ctx context.Context, q *callbackQuery) {
+	action, ok := parseTriageAction(q.Data)
+	if !ok || q.Message == nil {
+		c.answer(ctx, q.ID, "این دکمه دیگر فعال نیست.")
+		return
+	}
+
+	triage := model.Triage{
+		Source:     action.Source,
+		ExternalID: action.ExternalID,
+		UserID:     q.From.ID,
+		UserName:   q.From.displayName(),
+		State:      action.State,
+		ChatID:     q.Message.Chat.ID,
+		MessageID:  q.Message.MessageID,
+	}
+	if action.State == model.TriageRemind {
+		now := time.Now()
+		if !action.Deadline.After(now) {
+			c.answer(ctx, q.ID, "مهلت این پروژه تمام شده است.")
+			return
+		}
+		remindAt := action.Deadline.Add(-c.remindBefore)
+		if remindAt.Before(now) {
+			remindAt = now
+		}
+		triage.RemindAt = &remindAt
+	}
+
+	if err := c.store.Set(ctx, triage); err != nil {
+		slog.ErrorContext(ctx, "save triage failed", "error", err, "source", triage.Source, "external_id", triage.ExternalID)
+		c.answer(ctx, q.ID, "ثبت نشد؛ دوباره تلاش کنید.")
+		return
+	}
+	c.answer(ctx, q.ID, "ثبت شد: "+stateLabels[action.State])
+
+	all, err := c.store.ListByProject(ctx, triage.Source, triage.ExternalID)
+	if err != nil {
+		slog.ErrorContext(ctx, "list triage failed", "error", err)
+		return
+	}
+	payload := map[string]any{
+		"chat_id":    q.Message.Chat.ID,
+		"message_id": q.Message.MessageID,
+		"text":       withTriageStatus(q.Message.Text, all),
+	}
+	if q.Message.ReplyMarkup != nil {
+		payload["reply_markup"] = q.Message.ReplyMarkup
+	}
+	if _, err := c.bot.call(ctx, "editMessageText", payload, nil); err != nil && !strings.Contains(err.Error(), "message is not modified") {
+		slog.WarnContext(ctx, "edit alert message failed", "error", err)
+	}
+}
+
+func (c *Consumer) answer(ctx context.Context, queryID, text string) {
+	if _, err := c.bot.call(ctx, "answerCallbackQuery", map[string]any{"callback_query_id": queryID, "text": text}, nil); err != nil {
+		slog.WarnContext(ctx, "answer callback failed", "error", err)
+	}
+}
+
+// remind sends due reminders as replies to the alert they were requested on. Claiming marks
+// them sent first, so a failed send is logged rather than retried.
+func (c *Consumer) remind(ctx context.Context) {
+	ticker := time.NewTicker(c.remindEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		due, err := c.store.ClaimDueReminders(ctx, time.Now(), 20)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				slog.ErrorContext(ctx, "claim reminders failed", "error", err)
+			}
+			continue
+		}
+		for _, t := range due {
+			payload := map[string]any{
+				"chat_id": t.ChatID,
+				"text":    formatReminder(t),
+				"reply_parameters": map[string]any{
+					"message_id":                  t.MessageID,
+					"allow_sending_without_reply": true,
+				},
+			}
+			if _, err := c.bot.call(ctx, "sendMessage", payload, nil); err != nil {
+				slog.ErrorContext(ctx, "send reminder failed", "error", err, "source", t.Source, "external_id", t.ExternalID)
+			}
+		}
+	}
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+type update struct {
+	UpdateID      int64          `json:"update_id"`
+	Message       *message       `json:"message"`
+	CallbackQuery *callbackQuery `json:"callback_query"`
+}
+
+type message struct {
+	MessageID   int64           `json:"message_id"`
+	From        *user           `json:"from"`
+	Chat        chat            `json:"chat"`
+	Text        string          `json:"text"`
+	ReplyMarkup *inlineKeyboard `json:"reply_markup"`
+}
+
+type chat struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+type user struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Username  string `json:"username"`
+}
+
+func (u user) displayName() string {
+	if u.Username != "" {
+		return "@" + u.Username
+	}
+	return strings.TrimSpace(u.FirstName + " " + u.LastName)
+}
+
+type callbackQuery struct {
+	ID      string   `json:"id"`
+	From    user     `json:"from"`
+	Message *message `json:"message"`
+	Data    string   `json:"data"`
+}